@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dpakach/gorkin/lint"
+	"github.com/dpakach/gorkin/messages"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/pickles"
+	"github.com/dpakach/gorkin/reporter"
+)
+
+// runParse writes the parsed features with the reporter of every output
+func runParse(inv *invocation) int {
+	reporters := make([]reporter.Reporter, len(inv.outputs))
+	var envelopeReporters []*envelopeReporter
+	for i, o := range inv.outputs {
+		var err error
+		switch {
+		case o.dir:
+			reporters[i], err = reporter.NewDir(o.format, o.path)
+		case o.format == "ndjson":
+			r := &envelopeReporter{out: o.out}
+			envelopeReporters = append(envelopeReporters, r)
+			reporters[i] = r
+		default:
+			reporters[i], err = reporter.New(o.format, o.out)
+		}
+		if err != nil {
+			return inv.usageError("%v", err)
+		}
+	}
+
+	featureSet, sources, code := inv.load(len(envelopeReporters) != 0)
+	if featureSet == nil {
+		return code
+	}
+	for _, r := range envelopeReporters {
+		r.sources = sources
+	}
+	_, code = inv.compile(featureSet, code)
+	if err := reporter.Report(reporters, featureSet, inv.fileErrors); err != nil {
+		fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+		return exitParseErrors
+	}
+	return code
+}
+
+// envelopeReporter writes the features as Cucumber Messages, it is not a
+// registered format as the messages need the sources of the features
+type envelopeReporter struct {
+	out     io.Writer
+	sources map[string][]byte
+	newID   messages.IDGenerator
+}
+
+func (r *envelopeReporter) Start() error {
+	r.newID = messages.NewIncrementingIDGenerator()
+	return nil
+}
+
+func (r *envelopeReporter) Feature(feature *object.Feature) error {
+	return messages.WriteNDJSON(r.out, messages.FeatureEnvelopes(feature, string(r.sources[feature.URI]), r.newID))
+}
+
+func (r *envelopeReporter) Error(err *parser.FileError) error {
+	return nil
+}
+
+func (r *envelopeReporter) Finish() error {
+	return nil
+}
+
+// runList prints the location and the name of every compiled scenario
+func runList(inv *invocation) int {
+	featureSet, _, code := inv.load(false)
+	if featureSet == nil {
+		return code
+	}
+	compiled, code := inv.compile(featureSet, code)
+	for _, pickle := range compiled {
+		fmt.Fprintf(inv.out, "%v: %v\n", pickleLocation(pickle), pickle.Name)
+	}
+	return code
+}
+
+// compile compiles the features into pickles, the placeholders that are not
+// columns of the examples and the example rows with a wrong number of cells
+// are reported like the parse errors and change the exit code to
+// exitParseErrors
+func (inv *invocation) compile(featureSet *object.FeatureSet, code int) ([]pickles.Pickle, int) {
+	var compiled []pickles.Pickle
+	compiler := &pickles.Compiler{}
+	reporting := true
+	for i := range featureSet.Features {
+		featurePickles, err := compiler.Compile(&featureSet.Features[i])
+		if err != nil {
+			code = exitParseErrors
+			reporting = reporting && inv.reportError(err.Error())
+		}
+		compiled = append(compiled, featurePickles...)
+	}
+	return compiled, code
+}
+
+// pickleLocation returns the location of the scenario or the example row the
+// pickle was compiled from
+func pickleLocation(pickle pickles.Pickle) string {
+	line := pickle.LineNumber
+	if pickle.ExampleLineNumber != 0 {
+		line = pickle.ExampleLineNumber
+	}
+	return fmt.Sprintf("%v:%v", pickle.URI, line)
+}
+
+// runLint prints the lint findings, finding any problem is reported with the
+// exit code
+func runLint(inv *invocation) int {
+	featureSet, _, code := inv.load(false)
+	if featureSet == nil {
+		return code
+	}
+	findings := lint.FeatureSet(featureSet)
+	for _, finding := range findings {
+		fmt.Fprintln(inv.out, finding)
+	}
+	if code == exitOK && len(findings) != 0 {
+		return exitFindings
+	}
+	return code
+}
+
+// featureStats are the counts printed by the stats command
+type featureStats struct {
+	Features         int            `json:"features"`
+	Rules            int            `json:"rules"`
+	Scenarios        int            `json:"scenarios"`
+	ScenarioOutlines int            `json:"scenarioOutlines"`
+	Examples         int            `json:"examples"`
+	Steps            int            `json:"steps"`
+	Pickles          int            `json:"pickles"`
+	Tags             map[string]int `json:"tags"`
+}
+
+func (s *featureStats) add(feature *object.Feature, compiler *pickles.Compiler) {
+	s.Features++
+	s.Rules += len(feature.Rules)
+	if feature.Background != nil {
+		s.Steps += len(feature.Background.Steps)
+	}
+	scenarioTypes := append([]object.ScenarioType{}, feature.Scenarios...)
+	for _, rule := range feature.Rules {
+		if rule.Background != nil {
+			s.Steps += len(rule.Background.Steps)
+		}
+		scenarioTypes = append(scenarioTypes, rule.Scenarios...)
+	}
+	for _, scenarioType := range scenarioTypes {
+		switch scenario := scenarioType.(type) {
+		case *object.Scenario:
+			s.Scenarios++
+			s.Steps += len(scenario.Steps)
+		case *object.ScenarioOutline:
+			s.ScenarioOutlines++
+			s.Steps += len(scenario.Steps)
+			for _, table := range scenario.Tables {
+				if len(table) > 1 {
+					s.Examples += len(table) - 1
+				}
+			}
+		}
+	}
+
+	// The tags are counted on the compiled scenarios so the tags of the
+	// features, rules and examples count for every scenario they apply to,
+	// the placeholders missing from the examples are left to lint
+	compiled, _ := compiler.Compile(feature)
+	for _, pickle := range compiled {
+		s.Pickles++
+		for _, tag := range pickle.Tags {
+			s.Tags["@"+tag.Name]++
+		}
+	}
+}
+
+// runStats prints the number of features, scenarios, steps and tags
+func runStats(inv *invocation) int {
+	featureSet, _, code := inv.load(false)
+	if featureSet == nil {
+		return code
+	}
+	stats := &featureStats{Tags: map[string]int{}}
+	compiler := &pickles.Compiler{}
+	for i := range featureSet.Features {
+		stats.add(&featureSet.Features[i], compiler)
+	}
+
+	if inv.format == "json" {
+		encoder := json.NewEncoder(inv.out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats); err != nil {
+			fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+			return exitParseErrors
+		}
+		return code
+	}
+
+	fmt.Fprintf(inv.out, "Features:           %v\n", stats.Features)
+	fmt.Fprintf(inv.out, "Rules:              %v\n", stats.Rules)
+	fmt.Fprintf(inv.out, "Scenarios:          %v\n", stats.Scenarios)
+	fmt.Fprintf(inv.out, "Scenario Outlines:  %v\n", stats.ScenarioOutlines)
+	fmt.Fprintf(inv.out, "Examples:           %v\n", stats.Examples)
+	fmt.Fprintf(inv.out, "Steps:              %v\n", stats.Steps)
+	fmt.Fprintf(inv.out, "Pickles:            %v\n", stats.Pickles)
+	if len(stats.Tags) != 0 {
+		var tags []string
+		width := 0
+		for tag := range stats.Tags {
+			tags = append(tags, tag)
+			if len(tag) > width {
+				width = len(tag)
+			}
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if stats.Tags[tags[i]] != stats.Tags[tags[j]] {
+				return stats.Tags[tags[i]] > stats.Tags[tags[j]]
+			}
+			return tags[i] < tags[j]
+		})
+		fmt.Fprintln(inv.out, "Tags:")
+		for _, tag := range tags {
+			fmt.Fprintf(inv.out, "  %-*v  %v\n", width, tag, stats.Tags[tag])
+		}
+	}
+	return code
+}
+
+// runPickles prints the compiled scenarios as text or as Cucumber Messages
+func runPickles(inv *invocation) int {
+	featureSet, sources, code := inv.load(inv.format == "ndjson")
+	if featureSet == nil {
+		return code
+	}
+	compiled, code := inv.compile(featureSet, code)
+	if inv.format == "ndjson" {
+		var envelopes []*messages.Envelope
+		newID := messages.NewIncrementingIDGenerator()
+		for i := range featureSet.Features {
+			feature := &featureSet.Features[i]
+			for _, envelope := range messages.FeatureEnvelopes(feature, string(sources[feature.URI]), newID) {
+				if envelope.Pickle != nil {
+					envelopes = append(envelopes, envelope)
+				}
+			}
+		}
+		return inv.writeEnvelopes(envelopes, code)
+	}
+
+	for _, pickle := range compiled {
+		fmt.Fprintf(inv.out, "%v: %v", pickleLocation(pickle), pickle.Name)
+		for _, tag := range pickle.Tags {
+			fmt.Fprintf(inv.out, " @%v", tag.Name)
+		}
+		fmt.Fprintln(inv.out)
+		for _, step := range pickle.Steps {
+			fmt.Fprintf(inv.out, "  %v %v\n", step.Keyword, step.Text)
+			for _, row := range step.Table {
+				var cells []string
+				for _, cell := range row {
+					cells = append(cells, cell.Literal)
+				}
+				fmt.Fprintf(inv.out, "    | %v |\n", strings.Join(cells, " | "))
+			}
+			if step.DocString != nil {
+				fmt.Fprintf(inv.out, "    \"\"\"\n    %v\n    \"\"\"\n", strings.Replace(step.DocString.Content, "\n", "\n    ", -1))
+			}
+		}
+	}
+	return code
+}
+
+func (inv *invocation) writeEnvelopes(envelopes []*messages.Envelope, code int) int {
+	if err := messages.WriteNDJSON(inv.out, envelopes); err != nil {
+		fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+		return exitParseErrors
+	}
+	return code
+}
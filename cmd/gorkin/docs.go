@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dpakach/gorkin/docs"
+)
+
+// docsOptions are the flags of the docs subcommand
+type docsOptions struct {
+	dir   string
+	title string
+}
+
+// setupDocs registers the flags of the docs command, the site of the
+// features is written into a directory
+//
+// The features of the files that fail to parse are left out of the site and
+// the errors are reported with the exit code.
+func setupDocs(flags *flag.FlagSet) func(inv *invocation) int {
+	var opts docsOptions
+	flags.StringVar(&opts.dir, "dir", "site", "write the site into the `directory`")
+	flags.StringVar(&opts.title, "title", "Features", "the `title` of the site")
+
+	return func(inv *invocation) int {
+		featureSet, _, code := inv.load(false)
+		if featureSet == nil {
+			return code
+		}
+		files, err := docs.Build(featureSet, &docs.Options{Title: opts.title})
+		if err == nil {
+			err = files.Write(opts.dir)
+		}
+		if err != nil {
+			fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+			return exitParseErrors
+		}
+		return code
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/dpakach/gorkin/formatter"
+	"github.com/dpakach/gorkin/parser"
+)
+
+// fmtOptions are the flags of the fmt subcommand
+type fmtOptions struct {
+	write bool
+	list  bool
+	diff  bool
+}
+
+// setupFmt registers the flags of the fmt command, without any path the
+// standard input is formatted to the output
+//
+// Listing or diffing the files that are not formatted is reported as
+// findings with the exit code, rewriting them with -w is not.
+func setupFmt(flags *flag.FlagSet) func(inv *invocation) int {
+	var opts fmtOptions
+	flags.BoolVar(&opts.write, "w", false, "write the result to the file instead of the output")
+	flags.BoolVar(&opts.list, "l", false, "list the files whose formatting differs")
+	flags.BoolVar(&opts.diff, "d", false, "display the diffs instead of rewriting the files")
+
+	return func(inv *invocation) int {
+		if inv.tags != "" || len(inv.names) != 0 {
+			return inv.usageError("can not filter the scenarios of the formatted files")
+		}
+		paths := inv.paths
+		if len(paths) == 0 {
+			paths = []string{stdinPath}
+		}
+		files, selectors, err := resolvePaths(paths)
+		if err != nil {
+			fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+			return exitParseErrors
+		}
+		if len(selectors) != 0 {
+			return inv.usageError("can not format the lines of %v", selectors[0])
+		}
+
+		code := exitOK
+		for _, path := range files {
+			if path == stdinPath && opts.write {
+				return inv.usageError("can not use -w with the standard input")
+			}
+			uri, src, err := inv.read(path)
+			if err == nil {
+				var changed bool
+				changed, err = formatFile(uri, src, opts, inv.out)
+				if changed && (opts.list || opts.diff) && code == exitOK {
+					code = exitFindings
+				}
+			}
+			if err != nil {
+				var fileErr *parser.FileError
+				if !errors.As(err, &fileErr) {
+					fileErr = &parser.FileError{Path: uri, Err: err}
+				}
+				code = exitParseErrors
+				if !inv.reportFileError(fileErr) {
+					break
+				}
+			}
+		}
+		return code
+	}
+}
+
+// formatFile formats the source of a single file and reports the result as
+// asked by the options, changed is true when the formatting differs
+func formatFile(path string, src []byte, opts fmtOptions, out io.Writer) (changed bool, err error) {
+	featureSet, err := parser.ParseSource(path, src)
+	if err != nil {
+		return false, err
+	}
+	formatted := src
+	if len(featureSet.Features) != 0 {
+		formatted = formatter.Format(&featureSet.Features[0])
+	}
+
+	changed = !bytes.Equal(src, formatted)
+	if opts.list && changed {
+		fmt.Fprintln(out, path)
+	}
+	if opts.write && changed {
+		info, err := os.Stat(path)
+		if err != nil {
+			return changed, err
+		}
+		if err := ioutil.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			return changed, err
+		}
+	}
+	if opts.diff && changed {
+		out.Write(formatter.Diff(path+".orig", src, path, formatted))
+	}
+	if !opts.list && !opts.write && !opts.diff {
+		out.Write(formatted)
+	}
+	return changed, nil
+}
@@ -1,61 +1,217 @@
+// Command gorkin parses, lists, formats, lints and summarizes the Gherkin
+// feature files and documents them as a static site
 package main
 
 import (
-	"bytes"
+	"context"
+	"flag"
 	"fmt"
-	"github.com/dpakach/gorkin/lexer"
-	"github.com/dpakach/gorkin/parser"
-	"github.com/dpakach/gorkin/reporter"
 	"io"
-	"log"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"time"
+
+	"github.com/dpakach/gorkin/reporter"
 )
 
+// Exit codes of the gorkin command
+const (
+	exitOK          = 0
+	exitFindings    = 1
+	exitParseErrors = 2
+	exitUsage       = 3
+)
+
+// command is a subcommand of gorkin
+type command struct {
+	name    string
+	summary string
+	// formats are the output formats of the command, the first one is the
+	// default
+	formats []string
+	// multipleFormats allows --format to be repeated to write several
+	// outputs in a single run
+	multipleFormats bool
+	// setup registers the flags of the command and returns the function
+	// running it
+	setup func(flags *flag.FlagSet) func(inv *invocation) int
+}
+
+var commands = []*command{
+	{"parse", "print the parsed features", parseFormats(), true, noFlags(runParse)},
+	{"list", "list the scenarios", []string{"text"}, false, noFlags(runList)},
+	{"fmt", "format the feature files", nil, false, setupFmt},
+	{"lint", "check the features for common mistakes", []string{"text"}, false, noFlags(runLint)},
+	{"stats", "print the statistics of the features", []string{"text", "json"}, false, noFlags(runStats)},
+	{"pickles", "print the compiled scenarios", []string{"text", "ndjson"}, false, noFlags(runPickles)},
+	{"docs", "write a static HTML site of the features", nil, false, setupDocs},
+}
+
+// parseFormats returns the formats of the parse command, the text format of
+// the registered reporters comes first as the default
+func parseFormats() []string {
+	formats := []string{"text"}
+	for _, name := range reporter.Formats() {
+		if name != "text" {
+			formats = append(formats, name)
+		}
+	}
+	return append(formats, "ndjson")
+}
+
+func noFlags(run func(inv *invocation) int) func(flags *flag.FlagSet) func(inv *invocation) int {
+	return func(flags *flag.FlagSet) func(inv *invocation) int {
+		return run
+	}
+}
+
+func printUsage(out io.Writer) {
+	fmt.Fprint(out, "usage: gorkin [options] <command> [options] [paths...]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-8v  %v\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(out, `
+Options:
+  --tags expression  only include the scenarios matching the tag expression
+  --name pattern     only include the scenarios with a matching name, a
+                     case-insensitive substring or a /regexp/, may be repeated
+  --format name      output format, see "gorkin <command> -h", name:file writes
+                     the format to the file and name:dir/ writes a file for
+                     every feature, parse accepts it more than once
+  --output file      write the output to the file instead of the standard output
+  --max-errors n     report at most n parse errors, 0 for no limit
+  -j n               parse n files at the same time, defaults to GOMAXPROCS
+  --timings          write the time spent in every phase to stderr
+
+Paths are feature files, directories, globs or - for the standard input. A
+file may be followed by lines like path/to/file.feature:12:40 to select the
+scenarios at the lines.
+
+Exit codes:
+  0  success
+  1  findings, like lint problems or files that are not formatted
+  2  parse errors or files that can not be read, parse, list and pickles
+     also fail for the placeholders that are not columns of the examples
+     and the example rows with a wrong number of cells
+  3  usage error
+`)
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal(fmt.Errorf("Opps, Seems like you forgot to provide the path of the feature file"))
-		os.Exit(1)
+	// An interrupt cancels the parsing of the files still left
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the gorkin command with given arguments and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts options
+	globals := flag.NewFlagSet("gorkin", flag.ContinueOnError)
+	globals.SetOutput(stderr)
+	globals.Usage = func() { printUsage(stderr) }
+	opts.register(globals)
+	if err := globals.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
 	}
-	path := os.Args[1]
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		log.Fatal(fmt.Errorf("Invalid path provided, Make sure the path %q is correct", path))
-		os.Exit(1)
+
+	if globals.NArg() == 0 {
+		printUsage(stderr)
+		return exitUsage
 	}
-	fi, err := os.Stat(abs)
-	if os.IsNotExist(err) {
-		log.Fatal(fmt.Errorf("Error, Make sure the path %q exists", path))
-		os.Exit(1)
+	name := globals.Arg(0)
+	if name == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == name {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "gorkin: unknown command %q\n\n", name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet("gorkin "+name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gorkin %v [options] [paths...]\n\nThe %v command will %v", name, name, cmd.summary)
+		if len(cmd.formats) != 0 {
+			fmt.Fprintf(stderr, ", the formats are %v", cmd.formats)
+		}
+		fmt.Fprint(stderr, ".\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+	opts.register(flags)
+	runCommand := cmd.setup(flags)
+	if err := flags.Parse(globals.Args()[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
 	}
 
-	out := new(bytes.Buffer)
 	start := time.Now()
-	switch mode := fi.Mode(); {
-	case mode.IsDir():
-		err := filepath.Walk(abs, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				if ext := filepath.Ext(info.Name()); ext == ".feature" {
-					l := lexer.NewFromFile(path)
-					p := parser.New(l)
-					reporter.ParseAndReport(p, out)
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			log.Println(err)
-		}
-	case mode.IsRegular():
-		l := lexer.NewFromFile(path)
-		p := parser.New(l)
-		reporter.ParseAndReport(p, out)
-	}
-	io.Copy(os.Stdout, out)
-
-	io.WriteString(os.Stdout, fmt.Sprintf("\nCompleted in %v\n", time.Since(start)))
+	inv := &invocation{options: opts, ctx: ctx, name: name, paths: flags.Args(), stdin: stdin, stderr: stderr}
+	formats := opts.formats
+	if len(formats) == 0 {
+		// Without any format the commands write to the output, fmt too
+		formats = []string{""}
+		if len(cmd.formats) != 0 {
+			formats[0] = cmd.formats[0]
+		}
+	}
+	outputs, err := parseOutputs(formats, opts.output)
+	if err != nil {
+		return inv.usageError("%v", err)
+	}
+	if len(outputs) > 1 && !cmd.multipleFormats {
+		return inv.usageError("only one format can be written by the %v command", name)
+	}
+	for _, o := range outputs {
+		if !contains(cmd.formats, o.format) && len(opts.formats) != 0 {
+			return inv.usageError("unknown format %q, use one of %v", o.format, cmd.formats)
+		}
+		if o.dir && !cmd.multipleFormats {
+			return inv.usageError("the %v command can not write to the directory %v", name, o.path)
+		}
+	}
+	inv.outputs = outputs
+	if err := inv.openOutputs(stdout); err != nil {
+		return inv.usageError("%v", err)
+	}
+
+	code := runCommand(inv)
+	if err := inv.closeOutputs(); err != nil {
+		fmt.Fprintf(stderr, "gorkin %v: %v\n", name, err)
+		if code == exitOK {
+			code = exitParseErrors
+		}
+	}
+	if inv.showTimings {
+		inv.timings.total = time.Since(start)
+		inv.printTimings()
+	}
+	return code
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var featureFiles = map[string]string{
+	"good.feature": `@smoke
+Feature: shopping
+	Scenario: add to cart
+		Given an empty cart
+		When I add 2 apples
+		Then the cart has 2 items
+
+	Scenario Outline: eat <fruit>
+		Given I have <fruit>
+
+		Examples:
+			| fruit |
+			| pears |
+			| plums |
+`,
+	"lint.feature": `Feature: lint
+	Scenario: twice
+		Given a step
+	Scenario: twice
+		Given another step
+`,
+	"broken.feature": `Feature: broken
+	Scenario: one
+		| a |
+	Scenario: two
+		| b |
+`,
+	"placeholder.feature": `Feature: placeholder
+	Scenario Outline: eat <fruit>
+		Given I have <color>
+
+		Examples:
+			| fruit |
+			| pears |
+`,
+	"formatted.feature": `Feature: formatted
+
+  Scenario: one
+    Given a step
+`,
+}
+
+// writeFeatureFiles writes the feature files into a new directory
+func writeFeatureFiles(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gorkin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range featureFiles {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runInDir runs the command with the feature paths and the outputs of
+// --format and -dir inside dir, dir is removed from the output
+func runInDir(dir string, args []string, stdin string) (int, string, string) {
+	var inDir []string
+	for i, arg := range args {
+		switch {
+		case strings.Contains(arg, ".feature"):
+			arg = filepath.Join(dir, arg)
+		case strings.Contains(arg, ":") && i > 0 && args[i-1] == "--format":
+			format := strings.SplitN(arg, ":", 2)
+			arg = format[0] + ":" + filepath.Join(dir, format[1])
+			if strings.HasSuffix(format[1], "/") {
+				arg += "/"
+			}
+		case i > 0 && args[i-1] == "-dir":
+			arg = filepath.Join(dir, arg)
+		}
+		inDir = append(inDir, arg)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), inDir, strings.NewReader(stdin), &stdout, &stderr)
+	trim := func(out string) string {
+		return strings.Replace(out, dir+string(filepath.Separator), "", -1)
+	}
+	return code, trim(stdout.String()), trim(stderr.String())
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFeatureFiles(t)
+	defer os.RemoveAll(dir)
+
+	list := "good.feature:3: add to cart\ngood.feature:13: eat pears\ngood.feature:14: eat plums\n"
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"list", []string{"list", "good.feature"}, "", exitOK, list, ""},
+		{"list options before command", []string{"-j", "1", "list", "good.feature"}, "", exitOK, list, ""},
+		{"list jobs", []string{"list", "-j", "4", "good.feature", "formatted.feature"}, "", exitOK, "formatted.feature:3: one\n" + list, ""},
+		{"list name", []string{"list", "--name", "pears", "good.feature"}, "", exitOK, "good.feature:13: eat pears\n", ""},
+		{"list line", []string{"list", "good.feature:14"}, "", exitOK, "good.feature:14: eat plums\n", ""},
+		{"list stdin", []string{"list", "-"}, featureFiles["good.feature"], exitOK, strings.Replace(list, "good.feature", "<stdin>", -1), ""},
+		{
+			"list parse errors", []string{"list", "broken.feature", "good.feature"}, "", exitParseErrors, list,
+			"broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA\nbroken.feature:5:5: Expected token to be a STEP_TYPE but got TABLEDATA\n",
+		},
+		{"list missing file", []string{"list", "missing.feature"}, "", exitParseErrors, "", "gorkin list: stat missing.feature: no such file or directory\n"},
+		{"list no paths", []string{"list"}, "", exitUsage, "", "gorkin list: no paths given\n"},
+		{"list unknown format", []string{"list", "--format", "json", "good.feature"}, "", exitUsage, "", "gorkin list: unknown format \"json\", use one of [text]\n"},
+		{"list two formats", []string{"list", "--format", "text", "--format", "text:out.txt", "good.feature"}, "", exitUsage, "", "gorkin list: only one format can be written by the list command\n"},
+		{"parse same output", []string{"parse", "--format", "json", "--format", "text", "good.feature"}, "", exitUsage, "", "gorkin parse: more than one format written to the standard output\n"},
+		{"unknown flag", []string{"list", "--bogus", "good.feature"}, "", exitUsage, "", "flag provided but not defined: -bogus\n"},
+		{"fmt", []string{"fmt", "formatted.feature"}, "", exitOK, featureFiles["formatted.feature"], ""},
+		{"fmt stdin", []string{"fmt"}, featureFiles["formatted.feature"], exitOK, featureFiles["formatted.feature"], ""},
+		{"fmt list", []string{"fmt", "-l", "good.feature", "formatted.feature"}, "", exitFindings, "good.feature\n", ""},
+		{"fmt write stdin", []string{"fmt", "-w", "-"}, "", exitUsage, "", "gorkin fmt: can not use -w with the standard input\n"},
+		{"lint", []string{"lint", "good.feature"}, "", exitOK, "", ""},
+		{"lint findings", []string{"lint", "lint.feature"}, "", exitFindings, "lint.feature:4:2: scenario \"twice\" is already defined on line 2 (duplicate-scenario)\n", ""},
+		{
+			"stats", []string{"stats", "good.feature"}, "", exitOK,
+			"Features:           1\nRules:              0\nScenarios:          1\nScenario Outlines:  1\nExamples:           2\nSteps:              4\nPickles:            3\nTags:\n  @smoke  3\n", "",
+		},
+		{
+			"stats json", []string{"stats", "--format", "json", "good.feature"}, "", exitOK,
+			"{\n  \"features\": 1,\n  \"rules\": 0,\n  \"scenarios\": 1,\n  \"scenarioOutlines\": 1,\n  \"examples\": 2,\n  \"steps\": 4,\n  \"pickles\": 3,\n  \"tags\": {\n    \"@smoke\": 3\n  }\n}\n", "",
+		},
+		{
+			"pickles", []string{"pickles", "--tags", "@smoke", "--name", "eat", "good.feature"}, "", exitOK,
+			"good.feature:13: eat pears @smoke\n  Given I have pears\ngood.feature:14: eat plums @smoke\n  Given I have plums\n", "",
+		},
+		{"docs", []string{"docs", "-dir", "site", "good.feature"}, "", exitOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		code, stdout, stderr := runInDir(dir, tt.args, tt.stdin)
+		if code != tt.code {
+			t.Fatalf("%v: exit code mismatch, expected %v, got %v, stderr %q", tt.name, tt.code, code, stderr)
+		}
+		if stdout != tt.stdout {
+			t.Fatalf("%v: stdout mismatch, expected %q, got %q", tt.name, tt.stdout, stdout)
+		}
+		if !strings.HasPrefix(stderr, tt.stderr) || tt.stderr == "" && stderr != "" {
+			t.Fatalf("%v: stderr mismatch, expected %q, got %q", tt.name, tt.stderr, stderr)
+		}
+	}
+
+	for _, name := range []string{"index.html", "tags.html", "features/good.html"} {
+		if _, err := os.Stat(filepath.Join(dir, "site", name)); err != nil {
+			t.Fatalf("Expected the docs to write %v but got %v", name, err)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := writeFeatureFiles(t)
+	defer os.RemoveAll(dir)
+
+	broken := "broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA\nbroken.feature:5:5: Expected token to be a STEP_TYPE but got TABLEDATA\n"
+	placeholder := "placeholder.feature:3:3: placeholder <color> is not a column of the examples on line 6\n"
+	tests := []struct {
+		args   []string
+		stderr string
+	}{
+		{[]string{"parse", "broken.feature"}, broken},
+		{[]string{"list", "broken.feature", "placeholder.feature"}, broken + placeholder},
+		{[]string{"fmt", "broken.feature"}, broken},
+		{[]string{"lint", "broken.feature"}, broken},
+		{[]string{"stats", "broken.feature"}, broken},
+		{[]string{"pickles", "placeholder.feature"}, placeholder},
+		{[]string{"docs", "-dir", "site", "broken.feature"}, broken},
+		{[]string{"list", "--max-errors", "0", "broken.feature", "placeholder.feature"}, broken + placeholder},
+		{[]string{"list", "--max-errors", "3", "broken.feature", "placeholder.feature"}, broken + placeholder},
+		{[]string{"list", "--max-errors", "2", "broken.feature", "placeholder.feature"}, broken + "gorkin list: too many errors\n"},
+		{[]string{"list", "--max-errors", "1", "broken.feature", "placeholder.feature"}, "broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA\ngorkin list: too many errors\n"},
+		{[]string{"--max-errors", "1", "pickles", "broken.feature", "placeholder.feature"}, "broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA\ngorkin pickles: too many errors\n"},
+		{[]string{"fmt", "--max-errors", "1", "broken.feature"}, "broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA\ngorkin fmt: too many errors\n"},
+	}
+
+	for _, tt := range tests {
+		code, _, stderr := runInDir(dir, tt.args, "")
+		if code != exitParseErrors {
+			t.Fatalf("%v: exit code mismatch, expected %v, got %v", tt.args, exitParseErrors, code)
+		}
+		if stderr != tt.stderr {
+			t.Fatalf("%v: stderr mismatch, expected %q, got %q", tt.args, tt.stderr, stderr)
+		}
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	dir := writeFeatureFiles(t)
+	defer os.RemoveAll(dir)
+
+	args := []string{"parse", "--format", "json:out.json", "--format", "markdown:md/", "--format", "ndjson", "good.feature"}
+	code, stdout, stderr := runInDir(dir, args, "")
+	if code != exitOK || stderr != "" {
+		t.Fatalf("Expected exit code %v but got %v, stderr %q", exitOK, code, stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if !strings.HasPrefix(lines[0], `{"source":{"uri":"good.feature","data":"@smoke\nFeature: shopping\n`) {
+		t.Fatalf("Expected the source envelope first but got %v", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `{"gherkinDocument":{"uri":"good.feature"`) {
+		t.Fatalf("Expected the gherkin document envelope but got %v", lines[1])
+	}
+	if len(lines) != 5 {
+		t.Fatalf("Envelopes length mismatch, expected %v, got %v", 5, len(lines))
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "out.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte(`"title": "shopping"`)) {
+		t.Fatalf("Expected the feature in out.json but got %s", out)
+	}
+	md, err := ioutil.ReadFile(filepath.Join(dir, "md", "good.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(md, []byte("# Feature: shopping")) {
+		t.Fatalf("Expected the feature in good.md but got %s", md)
+	}
+
+	code, stdout, _ = runInDir(dir, []string{"parse", "good.feature"}, "")
+	if code != exitOK || !strings.Contains(stdout, "Title: shopping") {
+		t.Fatalf("Expected the text format by default but got %v %q", code, stdout)
+	}
+	code, stdout, _ = runInDir(dir, []string{"pickles", "--format", "ndjson", "good.feature"}, "")
+	if code != exitOK || strings.Count(stdout, `{"pickle":`) != 3 {
+		t.Fatalf("Expected 3 pickle envelopes but got %v %q", code, stdout)
+	}
+}
+
+func TestRunTimings(t *testing.T) {
+	dir := writeFeatureFiles(t)
+	defer os.RemoveAll(dir)
+
+	code, stdout, stderr := runInDir(dir, []string{"list", "-j", "2", "--timings", "good.feature", "formatted.feature"}, "")
+	if code != exitOK || stdout == "" {
+		t.Fatalf("Expected exit code %v and the scenarios but got %v %q", exitOK, code, stdout)
+	}
+	timings := regexp.MustCompile(`^gorkin list: resolve \S+, read \S+, parse \S+, merge \S+, filter \S+, output \S+, total \S+\n$`)
+	if !timings.MatchString(stderr) {
+		t.Fatalf("Expected the timings on stderr but got %q", stderr)
+	}
+}
+
+func TestRunHelp(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"help"}, exitOK, "usage: gorkin", ""},
+		{[]string{"-h"}, exitOK, "", "usage: gorkin"},
+		{[]string{"list", "-h"}, exitOK, "", "usage: gorkin list"},
+		{nil, exitUsage, "", "usage: gorkin"},
+		{[]string{"bogus"}, exitUsage, "", "gorkin: unknown command \"bogus\""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), tt.args, strings.NewReader(""), &stdout, &stderr)
+		if code != tt.code {
+			t.Fatalf("%v: exit code mismatch, expected %v, got %v", tt.args, tt.code, code)
+		}
+		if !strings.HasPrefix(stdout.String(), tt.stdout) || tt.stdout == "" && stdout.Len() != 0 {
+			t.Fatalf("%v: stdout mismatch, expected %q, got %q", tt.args, tt.stdout, stdout.String())
+		}
+		if !strings.HasPrefix(stderr.String(), tt.stderr) || tt.stderr == "" && stderr.Len() != 0 {
+			t.Fatalf("%v: stderr mismatch, expected %q, got %q", tt.args, tt.stderr, stderr.String())
+		}
+	}
+}
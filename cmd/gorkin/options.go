@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dpakach/gorkin/filter"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+// stdinPath is the path argument for the standard input and stdinURI is the
+// URI of the feature read from it
+const (
+	stdinPath = "-"
+	stdinURI  = "<stdin>"
+)
+
+// stringList is a flag that can be given multiple times
+type stringList []string
+
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ", ")
+}
+
+func (sl *stringList) Set(value string) error {
+	*sl = append(*sl, value)
+	return nil
+}
+
+// options are the options accepted by all the commands
+type options struct {
+	tags        string
+	names       stringList
+	formats     stringList
+	output      string
+	maxErrors   int
+	jobs        int
+	showTimings bool
+}
+
+// register adds the options to the flags, the values already set are kept as
+// the defaults so the options can be given before and after the command
+func (o *options) register(flags *flag.FlagSet) {
+	flags.StringVar(&o.tags, "tags", o.tags, "only include the scenarios matching the tag `expression`")
+	flags.Var(&o.names, "name", "only include the scenarios with a matching `name`, a case-insensitive substring or a /regexp/, may be repeated")
+	flags.Var(&o.formats, "format", "output `format`, name:file writes the format to the file and name:dir/ a file for every feature, may be repeated")
+	flags.StringVar(&o.output, "output", o.output, "write the output to the `file` instead of the standard output")
+	flags.IntVar(&o.maxErrors, "max-errors", o.maxErrors, "report at most `n` parse errors, 0 for no limit")
+	flags.IntVar(&o.jobs, "j", o.jobs, "parse `n` files at the same time, defaults to GOMAXPROCS")
+	flags.BoolVar(&o.showTimings, "timings", o.showTimings, "write the time spent in every phase to stderr")
+}
+
+// phaseTimings are the durations of the phases of a command
+type phaseTimings struct {
+	resolve time.Duration
+	parse   parser.Timings
+	filter  time.Duration
+	total   time.Duration
+}
+
+// invocation is a single run of a command
+type invocation struct {
+	options
+	ctx    context.Context
+	name   string
+	paths  []string
+	stdin  io.Reader
+	stderr io.Writer
+
+	// format and out are those of the first output, the commands writing a
+	// single format only use them
+	format  string
+	out     io.Writer
+	outputs []*output
+
+	// errorCount is the number of parse errors written to stderr,
+	// tooManyErrors is set once the maximum is reported and fileErrors are
+	// the errors of all the files that failed to load
+	errorCount    int
+	tooManyErrors bool
+	fileErrors    []*parser.FileError
+	timings       phaseTimings
+}
+
+// output is a format written to a file or to the standard output, dir is
+// true for the formats written as files into the directory of the path
+type output struct {
+	format string
+	path   string
+	dir    bool
+	out    io.Writer
+	close  func() error
+}
+
+// parseOutputs parses the --format values written as name[:file] into the
+// outputs, the formats without a file are written to the default path and a
+// file ending in a slash is a directory
+func parseOutputs(formats []string, defaultPath string) ([]*output, error) {
+	var outputs []*output
+	paths := map[string]bool{}
+	for _, format := range formats {
+		o := &output{format: format, path: defaultPath}
+		if i := strings.Index(format, ":"); i >= 0 {
+			o.format, o.path = format[:i], format[i+1:]
+			if o.path == "" {
+				return nil, fmt.Errorf("no file given for format %q", o.format)
+			}
+			o.dir = strings.HasSuffix(o.path, "/") || strings.HasSuffix(o.path, string(filepath.Separator))
+		}
+		if o.path == stdinPath {
+			o.path = ""
+		}
+		if paths[o.path] {
+			if o.path == "" {
+				return nil, fmt.Errorf("more than one format written to the standard output")
+			}
+			return nil, fmt.Errorf("more than one format written to %v", o.path)
+		}
+		paths[o.path] = true
+		outputs = append(outputs, o)
+	}
+	return outputs, nil
+}
+
+// openOutputs opens the files of the outputs, the outputs already opened are
+// closed when one fails
+func (inv *invocation) openOutputs(stdout io.Writer) error {
+	for i, o := range inv.outputs {
+		if o.dir {
+			o.close = func() error { return nil }
+			continue
+		}
+		out, closeOutput, err := openOutput(o.path, stdout)
+		if err != nil {
+			for _, opened := range inv.outputs[:i] {
+				opened.close()
+			}
+			return err
+		}
+		o.out, o.close = out, closeOutput
+	}
+	if len(inv.outputs) != 0 {
+		inv.format, inv.out = inv.outputs[0].format, inv.outputs[0].out
+	}
+	return nil
+}
+
+// closeOutputs closes the files of the outputs and returns the first error
+func (inv *invocation) closeOutputs() error {
+	var firstErr error
+	for _, o := range inv.outputs {
+		if err := o.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// printTimings writes the time spent in every phase to stderr, the read and
+// parse times are summed over the files parsed at the same time
+func (inv *invocation) printTimings() {
+	t := inv.timings
+	output := t.total - t.resolve - t.parse.Total - t.filter
+	fmt.Fprintf(
+		inv.stderr,
+		"gorkin %v: resolve %v, read %v, parse %v, merge %v, filter %v, output %v, total %v\n",
+		inv.name, t.resolve, t.parse.Read, t.parse.Parse, t.parse.Merge, t.filter, output, t.total,
+	)
+}
+
+func (inv *invocation) usageError(format string, a ...interface{}) int {
+	fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, fmt.Sprintf(format, a...))
+	return exitUsage
+}
+
+// reportFileError writes the errors of the file to stderr, false is returned
+// once more errors than the maximum number of errors are found, the files are
+// still parsed and the errors past the maximum only count in the exit code
+func (inv *invocation) reportFileError(err *parser.FileError) bool {
+	for _, message := range err.Messages() {
+		if !inv.reportError(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportError writes the error message to stderr and counts it towards the
+// maximum number of errors, false is returned once the maximum is reached
+func (inv *invocation) reportError(message string) bool {
+	if inv.maxErrors > 0 && inv.errorCount == inv.maxErrors {
+		if !inv.tooManyErrors {
+			fmt.Fprintf(inv.stderr, "gorkin %v: too many errors\n", inv.name)
+			inv.tooManyErrors = true
+		}
+		return false
+	}
+	fmt.Fprintln(inv.stderr, message)
+	inv.errorCount++
+	return true
+}
+
+// openOutput opens the output file, the standard output is used when there
+// is no file
+func openOutput(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" || path == stdinPath {
+		return stdout, func() error { return nil }, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
+
+// resolvePaths expands the directories and globs in the paths into the
+// feature files, the paths with lines like "file.feature:12" are returned as
+// location selectors too
+func resolvePaths(paths []string) (files []string, selectors []string, err error) {
+	seen := map[string]bool{}
+	add := func(path string) {
+		if path != stdinPath {
+			path = filepath.Clean(path)
+		}
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	// expand adds the file or the feature files inside the directory
+	expand := func(root string) error {
+		return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && (path == root || filepath.Ext(path) == ".feature") {
+				add(path)
+			}
+			return nil
+		})
+	}
+
+	for _, path := range paths {
+		switch {
+		case path == stdinPath:
+			add(path)
+		case strings.ContainsAny(path, "*?["):
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid pattern %q: %v", path, err)
+			}
+			if len(matches) == 0 {
+				return nil, nil, fmt.Errorf("no files match %q", path)
+			}
+			for _, match := range matches {
+				if err := expand(match); err != nil {
+					return nil, nil, err
+				}
+			}
+		default:
+			if _, err := os.Stat(path); err != nil {
+				location, locErr := filter.ParseLocation(path)
+				if locErr != nil || len(location.Lines) == 0 {
+					return nil, nil, err
+				}
+				if _, statErr := os.Stat(location.Path); statErr != nil {
+					return nil, nil, statErr
+				}
+				add(location.Path)
+				selectors = append(selectors, path)
+				continue
+			}
+			if err := expand(path); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return files, selectors, nil
+}
+
+// filter returns the filter built from the options and the location
+// selectors, nil when nothing is filtered
+func (inv *invocation) filter(selectors []string) (filter.Filter, error) {
+	var filters []filter.Filter
+	if inv.tags != "" {
+		tagExpression, err := filter.ParseTagExpression(inv.tags)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, tagExpression)
+	}
+
+	var nameFilters []filter.Filter
+	for _, name := range inv.names {
+		nameFilter, err := filter.ParseNameFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		nameFilters = append(nameFilters, nameFilter)
+	}
+	if len(nameFilters) != 0 {
+		filters = append(filters, filter.Or(nameFilters...))
+	}
+
+	if len(selectors) != 0 {
+		// The lines only select the scenarios of their own file, the other
+		// files are included in full
+		locationFilter, err := filter.ParseLocationFilter(selectors...)
+		if err != nil {
+			return nil, err
+		}
+		selectedFiles := &filter.LocationFilter{}
+		for _, location := range locationFilter.Locations {
+			selectedFiles.Locations = append(selectedFiles.Locations, filter.Location{Path: location.Path})
+		}
+		filters = append(filters, filter.Or(locationFilter, filter.Not(selectedFiles)))
+	}
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	return filter.And(filters...), nil
+}
+
+// load parses the features in the paths concurrently and filters them, with
+// keepSources the sources of the features are returned by their URI, the
+// files are parsed as they are read otherwise
+//
+// The parse errors are written to stderr and kept in inv.fileErrors, the
+// features of the files that parsed fine are still returned along with the
+// exit code for the errors
+func (inv *invocation) load(keepSources bool) (*object.FeatureSet, map[string][]byte, int) {
+	if len(inv.paths) == 0 {
+		return nil, nil, inv.usageError("no paths given")
+	}
+	resolveStart := time.Now()
+	files, selectors, err := resolvePaths(inv.paths)
+	if err != nil {
+		fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+		return nil, nil, exitParseErrors
+	}
+	f, err := inv.filter(selectors)
+	if err != nil {
+		return nil, nil, inv.usageError("%v", err)
+	}
+
+	code := exitOK
+	sources := make([]parser.Source, 0, len(files))
+	for _, path := range files {
+		if path != stdinPath {
+			sources = append(sources, parser.Source{Path: path})
+			continue
+		}
+		src, err := ioutil.ReadAll(inv.stdin)
+		if err != nil {
+			code = exitParseErrors
+			fileErr := &parser.FileError{Path: stdinURI, Err: err}
+			inv.fileErrors = append(inv.fileErrors, fileErr)
+			inv.reportFileError(fileErr)
+			continue
+		}
+		sources = append(sources, parser.Source{Path: stdinURI, Content: src})
+	}
+	inv.timings.resolve = time.Since(resolveStart)
+
+	featureSet, err := parser.ParseSources(inv.ctx, sources, &parser.ParseOptions{Workers: inv.jobs, Timings: &inv.timings.parse, KeepContent: keepSources})
+	if err != nil {
+		fileErrs, ok := err.(parser.FileErrors)
+		if !ok {
+			fmt.Fprintf(inv.stderr, "gorkin %v: %v\n", inv.name, err)
+			return nil, nil, exitParseErrors
+		}
+		code = exitParseErrors
+		inv.fileErrors = append(inv.fileErrors, fileErrs...)
+		for _, fileErr := range fileErrs {
+			if !inv.reportFileError(fileErr) {
+				break
+			}
+		}
+	}
+	contents := map[string][]byte{}
+	for _, source := range sources {
+		contents[source.Path] = source.Content
+	}
+
+	if f != nil {
+		filterStart := time.Now()
+		featureSet = filter.Apply(featureSet, f)
+		inv.timings.filter = time.Since(filterStart)
+	}
+	return featureSet, contents, code
+}
+
+// read returns the URI and the content of the path
+func (inv *invocation) read(path string) (string, []byte, error) {
+	if path == stdinPath {
+		src, err := ioutil.ReadAll(inv.stdin)
+		return stdinURI, src, err
+	}
+	src, err := ioutil.ReadFile(path)
+	return path, src, err
+}
@@ -0,0 +1,200 @@
+package docs
+
+// The assets are kept in the source so the site is built without reading any
+// file next to the binary
+
+const styleCSS = `:root {
+  --text: #1d2330;
+  --muted: #667085;
+  --accent: #2f6f4f;
+  --border: #d9dee7;
+  --background: #f7f8fa;
+}
+
+* { box-sizing: border-box; }
+
+body {
+  margin: 0;
+  font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif;
+  line-height: 1.5;
+  color: var(--text);
+  background: var(--background);
+}
+
+header {
+  display: flex;
+  flex-wrap: wrap;
+  align-items: center;
+  gap: 1em;
+  padding: 0.75em 2em;
+  background: #fff;
+  border-bottom: 1px solid var(--border);
+}
+
+header nav a { margin-right: 1em; }
+
+a { color: var(--accent); text-decoration: none; }
+a:hover { text-decoration: underline; }
+
+.site-title { font-weight: bold; font-size: 1.2em; color: var(--text); }
+
+main { max-width: 60em; margin: 0 auto; padding: 1em 2em 4em; }
+
+.search { position: relative; margin-left: auto; }
+.search input { width: 22em; padding: 0.4em 0.6em; border: 1px solid var(--border); border-radius: 4px; }
+
+#search-results {
+  position: absolute;
+  right: 0;
+  z-index: 1;
+  width: 30em;
+  max-height: 70vh;
+  overflow-y: auto;
+  margin: 0.25em 0 0;
+  padding: 0;
+  list-style: none;
+  background: #fff;
+  border: 1px solid var(--border);
+  border-radius: 4px;
+  box-shadow: 0 4px 12px rgba(0, 0, 0, 0.1);
+}
+
+#search-results li { padding: 0.4em 0.75em; border-bottom: 1px solid var(--border); }
+#search-results li:last-child { border-bottom: none; }
+#search-results .kind { color: var(--muted); font-size: 0.85em; margin-right: 0.5em; }
+
+.keyword { color: var(--accent); font-weight: bold; }
+.source, .summary, .count { color: var(--muted); font-size: 0.9em; }
+.description { white-space: pre-line; }
+.error { color: #b42318; }
+
+.tags { margin: 0.25em 0; }
+.tag {
+  display: inline-block;
+  margin: 0 0.25em 0.25em 0;
+  padding: 0 0.5em;
+  font-size: 0.85em;
+  background: #e8f1ec;
+  border-radius: 3px;
+}
+
+.cloud .tag { padding: 0.1em 0.5em; }
+.cloud .size-1 { font-size: 0.8em; }
+.cloud .size-2 { font-size: 1em; }
+.cloud .size-3 { font-size: 1.25em; }
+.cloud .size-4 { font-size: 1.5em; }
+.cloud .size-5 { font-size: 1.8em; }
+
+ul.features { padding-left: 1.25em; }
+ul.features .tags { display: inline; margin-left: 0.5em; }
+
+.toc { padding: 0.5em 1em; background: #fff; border: 1px solid var(--border); border-radius: 4px; }
+
+.background, .scenario, .rule {
+  margin: 1em 0;
+  padding: 0.5em 1em;
+  background: #fff;
+  border: 1px solid var(--border);
+  border-radius: 4px;
+}
+
+.rule { background: transparent; }
+.rule > h2 { margin-top: 0.25em; }
+
+.steps { list-style: none; padding-left: 0.5em; }
+.steps li { margin: 0.2em 0; }
+
+table { border-collapse: collapse; margin: 0.5em 0 0.5em 1em; }
+th, td { padding: 0.2em 0.75em; border: 1px solid var(--border); text-align: left; }
+th { background: var(--background); }
+
+.docstring {
+  margin: 0.5em 0 0.5em 1em;
+  padding: 0.5em 0.75em;
+  background: var(--background);
+  border-left: 3px solid var(--border);
+  white-space: pre-wrap;
+}
+
+.examples { margin-left: 0.5em; }
+.expanded summary { cursor: pointer; color: var(--muted); }
+.expanded-scenario { margin-left: 1em; }
+.expanded-scenario h4 { margin-bottom: 0; }
+
+:target { outline: 2px solid var(--accent); }
+`
+
+const searchJS = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var root = document.body.getAttribute("data-root") || "";
+  var index = window.gorkinSearchIndex || [];
+  var limit = 50;
+
+  // Every word of the query has to be found in the title, the tags or the
+  // text of the entry
+  function matches(entry, words) {
+    var haystack = (entry.t + " " + (entry.g || []).map(function (tag) {
+      return "@" + tag;
+    }).join(" ") + " " + (entry.x || "")).toLowerCase();
+    for (var i = 0; i < words.length; i++) {
+      if (haystack.indexOf(words[i]) < 0) {
+        return false;
+      }
+    }
+    return true;
+  }
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    while (results.firstChild) {
+      results.removeChild(results.firstChild);
+    }
+    if (words.length === 0) {
+      results.hidden = true;
+      return;
+    }
+    var found = 0;
+    for (var i = 0; i < index.length && found < limit; i++) {
+      if (!matches(index[i], words)) {
+        continue;
+      }
+      found++;
+      var item = document.createElement("li");
+      var kind = document.createElement("span");
+      kind.className = "kind";
+      kind.textContent = index[i].k;
+      var link = document.createElement("a");
+      link.href = root + index[i].u;
+      link.textContent = index[i].t;
+      item.appendChild(kind);
+      item.appendChild(link);
+      results.appendChild(item);
+    }
+    if (found === 0) {
+      var empty = document.createElement("li");
+      empty.textContent = "No results";
+      results.appendChild(empty);
+    }
+    results.hidden = false;
+  }
+
+  input.addEventListener("input", search);
+  input.addEventListener("keydown", function (event) {
+    if (event.key === "Escape") {
+      input.value = "";
+      search();
+    } else if (event.key === "Enter") {
+      var first = results.querySelector("a");
+      if (first) {
+        window.location.href = first.href;
+      }
+    }
+  });
+  document.addEventListener("click", function (event) {
+    if (!results.contains(event.target) && event.target !== input) {
+      results.hidden = true;
+    }
+  });
+})();
+`
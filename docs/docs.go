@@ -0,0 +1,360 @@
+// Package docs renders features into a static HTML site, the living
+// documentation of a project
+//
+// The site has an index of the features by directory, an index of the
+// scenarios by tag and a page for every feature. The stylesheet, the script
+// and the search index are written next to the pages so the site works
+// offline, opened straight from the disk.
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dpakach/gorkin/internal/paths"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/pickles"
+)
+
+// Options are the options of the generated site
+type Options struct {
+	// Title is the title of the site, "Features" by default
+	Title string
+}
+
+// Files are the files of the site by their slash separated path
+type Files map[string][]byte
+
+// Write writes the files into the directory, creating the directories on
+// the way
+func (f Files) Write(dir string) error {
+	for _, name := range f.Names() {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, f[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted paths of the files
+func (f Files) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// site is the data of the templates shared by every page
+type site struct {
+	Title    string
+	Features []*featurePage
+	Dirs     []*dirEntry
+	Tags     []*tagEntry
+}
+
+// page is the data of a single page, Root is the relative path from the
+// page back to the root of the site
+type page struct {
+	*site
+	Root    string
+	Feature *featurePage
+}
+
+type dirEntry struct {
+	Name     string
+	Features []*featurePage
+}
+
+type tagEntry struct {
+	Name      string
+	Size      int
+	Scenarios []tagScenario
+}
+
+type tagScenario struct {
+	Name    string
+	Feature *featurePage
+	Anchor  string
+}
+
+type featurePage struct {
+	Feature   *object.Feature
+	Path      string
+	Source    string
+	Root      string
+	Scenarios []scenarioView
+	Rules     []ruleView
+}
+
+type ruleView struct {
+	Rule      *object.Rule
+	Scenarios []scenarioView
+}
+
+type scenarioView struct {
+	Root        string
+	Keyword     string
+	Title       string
+	Description string
+	Tags        []string
+	Anchor      string
+	Steps       []object.Step
+	Outline     bool
+	Examples    []examplesView
+	Expanded    []object.Scenario
+	Error       error
+}
+
+type examplesView struct {
+	Keyword     string
+	Name        string
+	Tags        []string
+	Description string
+	Table       object.Table
+}
+
+// searchEntry is an entry of the search index, the names are short to keep
+// the index small
+type searchEntry struct {
+	Title string   `json:"t"`
+	Kind  string   `json:"k"`
+	URL   string   `json:"u"`
+	Tags  []string `json:"g,omitempty"`
+	Text  string   `json:"x,omitempty"`
+}
+
+// Build renders the site of the features
+func Build(featureSet *object.FeatureSet, opts *Options) (Files, error) {
+	s := &site{Title: "Features"}
+	if opts != nil && opts.Title != "" {
+		s.Title = opts.Title
+	}
+	if featureSet != nil {
+		s.addFeatures(featureSet.Features)
+	}
+
+	files := Files{
+		"assets/style.css": []byte(styleCSS),
+		"assets/search.js": []byte(searchJS),
+	}
+	index, err := s.searchIndex()
+	if err != nil {
+		return nil, err
+	}
+	files["assets/search-index.js"] = index
+
+	render := func(name string, t templateName, p *page) error {
+		var buf bytes.Buffer
+		if err := templates[t].ExecuteTemplate(&buf, "layout", p); err != nil {
+			return fmt.Errorf("rendering %v: %v", name, err)
+		}
+		files[name] = buf.Bytes()
+		return nil
+	}
+	if err := render("index.html", indexTemplate, &page{site: s}); err != nil {
+		return nil, err
+	}
+	if err := render("tags.html", tagsTemplate, &page{site: s}); err != nil {
+		return nil, err
+	}
+	for _, feature := range s.Features {
+		p := &page{site: s, Root: feature.Root, Feature: feature}
+		if err := render(feature.Path, featureTemplate, p); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// addFeatures adds the pages of the features, their directories and tags
+func (s *site) addFeatures(features []object.Feature) {
+	uris := make([]string, len(features))
+	for i := range features {
+		uris[i] = filepath.ToSlash(features[i].URI)
+	}
+	root := paths.CommonDir(uris)
+
+	compiler := &pickles.Compiler{}
+	taken := map[string]bool{}
+	dirs := map[string]*dirEntry{}
+	tags := map[string]*tagEntry{}
+	for i := range features {
+		feature := &features[i]
+		source := strings.TrimPrefix(strings.TrimPrefix(uris[i], root), "/")
+		p := &featurePage{Feature: feature, Source: source, Path: pagePath(source, taken)}
+		p.Root = strings.Repeat("../", strings.Count(p.Path, "/"))
+		p.Scenarios = scenarioViews(feature.Scenarios, p.Root)
+		for j := range feature.Rules {
+			rule := &feature.Rules[j]
+			p.Rules = append(p.Rules, ruleView{Rule: rule, Scenarios: scenarioViews(rule.Scenarios, p.Root)})
+		}
+		s.Features = append(s.Features, p)
+
+		dir := path.Dir(source)
+		if dirs[dir] == nil {
+			dirs[dir] = &dirEntry{Name: dir}
+			s.Dirs = append(s.Dirs, dirs[dir])
+		}
+		dirs[dir].Features = append(dirs[dir].Features, p)
+
+		// The tags are collected from the compiled scenarios so the tags of
+		// the features, rules and examples count for all of their scenarios,
+		// the placeholder errors are shown with the expanded outlines
+		compiled, _ := compiler.Compile(feature)
+		for _, pickle := range compiled {
+			for _, tag := range pickle.Tags {
+				if tags[tag.Name] == nil {
+					tags[tag.Name] = &tagEntry{Name: tag.Name}
+					s.Tags = append(s.Tags, tags[tag.Name])
+				}
+				scenario := tagScenario{Name: pickle.Name, Feature: p, Anchor: anchor(pickle.LineNumber)}
+				tags[tag.Name].Scenarios = append(tags[tag.Name].Scenarios, scenario)
+			}
+		}
+	}
+
+	sort.Slice(s.Dirs, func(i, j int) bool { return s.Dirs[i].Name < s.Dirs[j].Name })
+	for _, dir := range s.Dirs {
+		sort.SliceStable(dir.Features, func(i, j int) bool { return dir.Features[i].Source < dir.Features[j].Source })
+	}
+	sort.Slice(s.Tags, func(i, j int) bool { return s.Tags[i].Name < s.Tags[j].Name })
+	s.sizeTags()
+}
+
+// sizeTags sets the size of the tags in the tag cloud from 1 to 5, relative
+// to the tag with the most scenarios
+func (s *site) sizeTags() {
+	most := 0
+	for _, tag := range s.Tags {
+		if len(tag.Scenarios) > most {
+			most = len(tag.Scenarios)
+		}
+	}
+	for _, tag := range s.Tags {
+		tag.Size = 1 + 4*len(tag.Scenarios)/most
+	}
+}
+
+// searchIndex returns the script with the features and the scenarios to
+// search, a script works where reading a JSON file from the disk does not
+func (s *site) searchIndex() ([]byte, error) {
+	entries := []searchEntry{}
+	for _, feature := range s.Features {
+		entries = append(entries, searchEntry{
+			Title: feature.Feature.Title,
+			Kind:  "Feature",
+			URL:   feature.Path,
+			Tags:  feature.Feature.Tags,
+			Text:  feature.Feature.Description,
+		})
+		scenarios := append([]scenarioView{}, feature.Scenarios...)
+		for _, rule := range feature.Rules {
+			scenarios = append(scenarios, rule.Scenarios...)
+		}
+		for _, scenario := range scenarios {
+			var text []string
+			for _, step := range scenario.Steps {
+				text = append(text, step.Token.Literal+" "+step.Text)
+			}
+			entries = append(entries, searchEntry{
+				Title: scenario.Title,
+				Kind:  scenario.Keyword,
+				URL:   feature.Path + "#" + scenario.Anchor,
+				Tags:  scenario.Tags,
+				Text:  strings.Join(text, "\n"),
+			})
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("var gorkinSearchIndex = " + string(data) + ";\n"), nil
+}
+
+// scenarioViews returns the views of the scenarios on the page with given
+// root, the outlines are expanded into the scenarios of their examples
+func scenarioViews(scenarioTypes []object.ScenarioType, root string) []scenarioView {
+	var views []scenarioView
+	for _, scenarioType := range scenarioTypes {
+		switch scenario := scenarioType.(type) {
+		case *object.Scenario:
+			views = append(views, scenarioView{
+				Root:        root,
+				Keyword:     scenario.Keyword,
+				Title:       scenario.ScenarioText,
+				Description: scenario.Description,
+				Tags:        scenario.Tags,
+				Anchor:      anchor(scenario.LineNumber),
+				Steps:       scenario.Steps,
+			})
+		case *object.ScenarioOutline:
+			view := scenarioView{
+				Root:        root,
+				Keyword:     scenario.Keyword,
+				Title:       scenario.ScenarioText,
+				Description: scenario.Description,
+				Tags:        scenario.Tags,
+				Anchor:      anchor(scenario.LineNumber),
+				Steps:       scenario.Steps,
+				Outline:     true,
+			}
+			for i, table := range scenario.Tables {
+				examples := examplesView{Keyword: "Examples", Table: table}
+				if i < len(scenario.TableTokens) {
+					examples.Keyword = scenario.TableTokens[i].Literal
+				}
+				if i < len(scenario.TableNames) {
+					examples.Name = scenario.TableNames[i]
+				}
+				if i < len(scenario.TableTags) {
+					examples.Tags = scenario.TableTags[i]
+				}
+				if i < len(scenario.TableDescriptions) {
+					examples.Description = scenario.TableDescriptions[i]
+				}
+				view.Examples = append(view.Examples, examples)
+			}
+			view.Expanded, view.Error = scenario.Expand()
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+// pagePath returns the path of the page of the feature file, mirroring the
+// directories of the files under features/
+func pagePath(source string, taken map[string]bool) string {
+	clean := strings.Map(func(r rune) rune {
+		if r == '/' || r == '.' || r == '-' || r == '_' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSuffix(source, path.Ext(source)))
+	clean = strings.Replace(clean, "..", "__", -1)
+
+	name := "features/" + clean + ".html"
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("features/%v-%v.html", clean, i)
+	}
+	taken[name] = true
+	return name
+}
+
+func anchor(line int) string {
+	return fmt.Sprintf("line-%v", line)
+}
@@ -0,0 +1,209 @@
+package docs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/internal/paths"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+var docsFeatures = map[string]string{
+	"specs/shop/cart.feature": `@shop
+Feature: Cart
+	Adding <things> to the cart
+
+	Background:
+		Given an empty cart
+
+	@smoke
+	Scenario: add one
+		When I add 1 "apple"
+		Then the cart has:
+			| item  | count |
+			| apple | 1     |
+
+	Scenario Outline: add <count> <item>
+		When I add <count> "<item>"
+
+		@fruit
+		Examples:
+			| count | item   |
+			| 2     | banana |
+			| 3     | pear   |
+`,
+	"specs/checkout.feature": `Feature: Checkout
+	Rule: pay
+		@smoke @payment
+		Scenario: pay by card
+			Given the payment
+				"""
+				<card> number
+				"""
+`,
+}
+
+func parseFeatures(t *testing.T) *object.FeatureSet {
+	featureSet := &object.FeatureSet{}
+	for _, uri := range []string{"specs/checkout.feature", "specs/shop/cart.feature"} {
+		fs, err := parser.ParseSource(uri, []byte(docsFeatures[uri]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		featureSet.Merge(fs)
+	}
+	return featureSet
+}
+
+func TestBuild(t *testing.T) {
+	files, err := Build(parseFeatures(t), &Options{Title: "Shop <specs>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"assets/search-index.js",
+		"assets/search.js",
+		"assets/style.css",
+		"features/checkout.html",
+		"features/shop/cart.html",
+		"index.html",
+		"tags.html",
+	}
+	if strings.Join(files.Names(), ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("Files mismatch, expected %v, got %v", expected, files.Names())
+	}
+
+	tests := []struct {
+		file     string
+		contains []string
+	}{
+		{"index.html", []string{
+			"<title>Shop &lt;specs&gt;</title>",
+			`<h3 class="dir">Top level</h3>`,
+			`<h3 class="dir">shop/</h3>`,
+			`<a href="features/shop/cart.html">Cart</a>`,
+			`<a class="tag size-5" href="tags.html#tag-shop" title="3 scenarios">@shop</a>`,
+			`<a class="tag size-3" href="tags.html#tag-fruit" title="2 scenarios">@fruit</a>`,
+		}},
+		{"tags.html", []string{
+			`<section class="tag-index" id="tag-smoke">`,
+			`<a href="features/shop/cart.html#line-9">add one</a>`,
+			`<a href="features/checkout.html#line-4">pay by card</a>`,
+			`<a href="features/shop/cart.html#line-15">add 3 pear</a>`,
+		}},
+		{"features/shop/cart.html", []string{
+			`<link rel="stylesheet" href="../../assets/style.css">`,
+			`<body data-root="../../">`,
+			`<p class="description">Adding &lt;things&gt; to the cart</p>`,
+			`<span class="keyword">Background:</span>`,
+			`<section class="scenario" id="line-15">`,
+			`<a class="tag" href="../../tags.html#tag-fruit">@fruit</a>`,
+			`<th>count</th><th>item</th>`,
+			`<summary>2 scenarios from the examples</summary>`,
+			`<h4>add 2 banana</h4>`,
+			`<span class="keyword">When</span> I add 3 &#34;pear&#34;`,
+			`<td>apple</td><td>1</td>`,
+		}},
+		{"features/checkout.html", []string{
+			`<h2><span class="keyword">Rule:</span> pay</h2>`,
+			`<pre class="docstring">&lt;card&gt; number</pre>`,
+			`<script src="../assets/search.js"></script>`,
+		}},
+	}
+
+	for _, tt := range tests {
+		content := string(files[tt.file])
+		for _, s := range tt.contains {
+			if !strings.Contains(content, s) {
+				t.Fatalf("Expected %v to contain %q but got %v", tt.file, s, content)
+			}
+		}
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	files, err := Build(parseFeatures(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(files["assets/search-index.js"])
+	if !strings.HasPrefix(script, "var gorkinSearchIndex = ") || !strings.HasSuffix(script, ";\n") {
+		t.Fatalf("Expected the index to be assigned in the script but got %v", script)
+	}
+
+	var entries []searchEntry
+	data := strings.TrimSuffix(strings.TrimPrefix(script, "var gorkinSearchIndex = "), ";\n")
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("Entries length mismatch, expected 5, got %v", len(entries))
+	}
+	entry := entries[1]
+	if entry.Title != "pay by card" || entry.URL != "features/checkout.html#line-4" || entry.Kind != "Scenario" {
+		t.Fatalf("Entry mismatch, expected pay by card, got %+v", entry)
+	}
+	if entry.Text != "Given the payment" || strings.Join(entry.Tags, " ") != "smoke payment" {
+		t.Fatalf("Entry mismatch, expected the steps and the tags, got %+v", entry)
+	}
+	// The markup is escaped so the index can not close the script
+	if strings.Contains(script, "<") {
+		t.Fatalf("Expected no < in the script but got %v", script)
+	}
+}
+
+func TestPagePath(t *testing.T) {
+	tests := []struct {
+		uris     []string
+		expected []string
+	}{
+		{[]string{"a.feature"}, []string{"features/a.html"}},
+		{[]string{"/abs/specs/a.feature", "/abs/specs/sub/b.feature"}, []string{"features/a.html", "features/sub/b.html"}},
+		{[]string{"specs/a.feature", "other/a.feature"}, []string{"features/specs/a.html", "features/other/a.html"}},
+		{[]string{"<stdin>", "x/a b.feature"}, []string{"features/_stdin_.html", "features/x/a_b.html"}},
+		{[]string{"a.feature", "a.txt"}, []string{"features/a.html", "features/a-2.html"}},
+	}
+
+	for _, tt := range tests {
+		root := paths.CommonDir(tt.uris)
+		taken := map[string]bool{}
+		for i, uri := range tt.uris {
+			got := pagePath(strings.TrimPrefix(strings.TrimPrefix(uri, root), "/"), taken)
+			if got != tt.expected[i] {
+				t.Fatalf("Path mismatch for %v, expected %v, got %v", uri, tt.expected[i], got)
+			}
+		}
+	}
+}
+
+func TestFilesWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorkin-docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := Build(&object.FeatureSet{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["features/deep/page.html"] = []byte("page")
+	if err := files.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files.Names() {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != string(files[name]) {
+			t.Fatalf("Content mismatch of %v", name)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package docs
+
+import (
+	"html/template"
+	"strings"
+)
+
+type templateName int
+
+// The pages of the site, each one is the layout with its own content
+const (
+	indexTemplate templateName = iota
+	tagsTemplate
+	featureTemplate
+)
+
+var templates = map[templateName]*template.Template{}
+
+func init() {
+	layout := template.Must(template.New("layout").Funcs(templateFuncs).Parse(layoutHTML))
+	for name, content := range map[templateName]string{
+		indexTemplate:   indexHTML,
+		tagsTemplate:    tagsHTML,
+		featureTemplate: featureHTML,
+	} {
+		templates[name] = template.Must(template.Must(layout.Clone()).Parse(content))
+	}
+}
+
+// tagLink is a tag linking to its scenarios on the tags page
+type tagLink struct {
+	Name string
+	URL  string
+}
+
+var templateFuncs = template.FuncMap{
+	"tagLinks": func(root string, tags []string) []tagLink {
+		links := make([]tagLink, 0, len(tags))
+		for _, tag := range tags {
+			links = append(links, tagLink{Name: tag, URL: root + "tags.html#tag-" + tag})
+		}
+		return links
+	},
+	// paragraphs splits the description at the blank lines
+	"paragraphs": func(description string) []string {
+		var paragraphs []string
+		for _, paragraph := range strings.Split(description, "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				paragraphs = append(paragraphs, paragraph)
+			}
+		}
+		return paragraphs
+	},
+}
+
+const layoutHTML = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{block "title" .}}{{.Title}}{{end}}</title>
+<link rel="stylesheet" href="{{.Root}}assets/style.css">
+</head>
+<body data-root="{{.Root}}">
+<header>
+<a class="site-title" href="{{.Root}}index.html">{{.Title}}</a>
+<nav><a href="{{.Root}}index.html">Features</a> <a href="{{.Root}}tags.html">Tags</a></nav>
+<div class="search">
+<input id="search" type="search" placeholder="Search features and scenarios" autocomplete="off" aria-label="Search">
+<ol id="search-results" hidden></ol>
+</div>
+</header>
+<main>
+{{template "content" .}}
+</main>
+<script src="{{.Root}}assets/search-index.js"></script>
+<script src="{{.Root}}assets/search.js"></script>
+</body>
+</html>
+{{end}}
+
+{{define "cloud"}}<p class="cloud">{{range .Tags}}<a class="tag size-{{.Size}}" href="{{$.Root}}tags.html#tag-{{.Name}}" title="{{len .Scenarios}} scenarios">@{{.Name}}</a> {{end}}</p>{{end}}
+
+{{define "tags"}}{{if .}}<p class="tags">{{range .}}<a class="tag" href="{{.URL}}">@{{.Name}}</a> {{end}}</p>{{end}}{{end}}
+
+{{define "description"}}{{range paragraphs .}}<p class="description">{{.}}</p>{{end}}{{end}}
+
+{{define "table"}}<table>{{range $i, $row := .}}<tr>{{range $row}}{{if eq $i 0}}<th>{{.Literal}}</th>{{else}}<td>{{.Literal}}</td>{{end}}{{end}}</tr>{{end}}</table>{{end}}
+
+{{define "datatable"}}<table>{{range .}}<tr>{{range .}}<td>{{.Literal}}</td>{{end}}</tr>{{end}}</table>{{end}}
+
+{{define "steps"}}{{if .}}<ol class="steps">{{range .}}<li><span class="keyword">{{.Token.Literal}}</span> {{.Text}}{{with .Table}}{{template "datatable" .}}{{end}}{{with .DocString}}<pre class="docstring">{{.Content}}</pre>{{end}}</li>{{end}}</ol>{{end}}{{end}}
+
+{{define "background"}}<section class="background">
+<h2><span class="keyword">{{.Keyword}}:</span> {{.Title}}</h2>
+{{template "steps" .Steps}}
+</section>{{end}}
+
+{{define "scenario"}}<section class="scenario" id="{{.Anchor}}">
+{{template "tags" (tagLinks .Root .Tags)}}
+<h3><span class="keyword">{{.Keyword}}:</span> {{.Title}}</h3>
+{{template "description" .Description}}
+{{template "steps" .Steps}}
+{{range .Examples}}<div class="examples">
+{{template "tags" (tagLinks $.Root .Tags)}}
+<h4><span class="keyword">{{.Keyword}}:</span> {{.Name}}</h4>
+{{template "description" .Description}}
+{{template "table" .Table}}
+</div>
+{{end}}{{if .Outline}}<details class="expanded">
+<summary>{{len .Expanded}} scenarios from the examples</summary>
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+{{range .Expanded}}<div class="expanded-scenario">
+<h4>{{.ScenarioText}}</h4>
+{{template "steps" .Steps}}
+</div>
+{{end}}</details>
+{{end}}</section>
+{{end}}`
+
+const indexHTML = `{{define "content"}}<h1>{{.Title}}</h1>
+<p class="summary">{{len .Features}} features in {{len .Dirs}} directories, {{len .Tags}} tags</p>
+{{if .Tags}}<section>
+<h2>Tags</h2>
+{{template "cloud" .}}
+</section>
+{{end}}<section>
+<h2>Features by directory</h2>
+{{range .Dirs}}<h3 class="dir">{{if eq .Name "."}}Top level{{else}}{{.Name}}/{{end}}</h3>
+<ul class="features">
+{{range .Features}}<li><a href="{{$.Root}}{{.Path}}">{{.Feature.Title}}</a> <span class="source">{{.Source}}</span>{{template "tags" (tagLinks $.Root .Feature.Tags)}}</li>
+{{end}}</ul>
+{{end}}</section>
+{{end}}`
+
+const tagsHTML = `{{define "title"}}Tags - {{.Title}}{{end}}
+
+{{define "content"}}<h1>Tags</h1>
+{{template "cloud" .}}
+{{range .Tags}}<section class="tag-index" id="tag-{{.Name}}">
+<h2>@{{.Name}} <span class="count">{{len .Scenarios}}</span></h2>
+<ul>
+{{range .Scenarios}}<li><a href="{{$.Root}}{{.Feature.Path}}#{{.Anchor}}">{{.Name}}</a> <span class="source">{{.Feature.Source}}</span></li>
+{{end}}</ul>
+</section>
+{{end}}{{end}}`
+
+const featureHTML = `{{define "title"}}{{.Feature.Feature.Title}} - {{.Title}}{{end}}
+
+{{define "content"}}{{with .Feature}}<p class="source">{{.Source}}</p>
+{{template "tags" (tagLinks $.Root .Feature.Tags)}}
+<h1><span class="keyword">{{.Feature.Token.Literal}}:</span> {{.Feature.Title}}</h1>
+{{template "description" .Feature.Description}}
+{{if or .Scenarios .Rules}}<nav class="toc">
+<ul>
+{{range .Scenarios}}<li><a href="#{{.Anchor}}">{{.Title}}</a></li>
+{{end}}{{range .Rules}}<li>{{.Rule.Title}}<ul>
+{{range .Scenarios}}<li><a href="#{{.Anchor}}">{{.Title}}</a></li>
+{{end}}</ul></li>
+{{end}}</ul>
+</nav>
+{{end}}{{with .Feature.Background}}{{template "background" .}}{{end}}
+{{range .Scenarios}}{{template "scenario" .}}{{end}}
+{{range .Rules}}<section class="rule">
+{{template "tags" (tagLinks $.Root .Rule.Tags)}}
+<h2><span class="keyword">{{.Rule.Token.Literal}}:</span> {{.Rule.Title}}</h2>
+{{template "description" .Rule.Description}}
+{{with .Rule.Background}}{{template "background" .}}{{end}}
+{{range .Scenarios}}{{template "scenario" .}}{{end}}
+</section>
+{{end}}{{end}}{{end}}`
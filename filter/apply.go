@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/pickles"
+)
+
+// Scenario is a compiled scenario matched by the filters along with the
+// feature, rule and scenario or scenario outline it was compiled from
+//
+// Rule is nil for the scenarios outside of rules
+type Scenario struct {
+	Feature      *object.Feature
+	Rule         *object.Rule
+	ScenarioType object.ScenarioType
+	Pickle       *pickles.Pickle
+
+	// tagNames are collected once for all the filters matching the scenario
+	tagNames []string
+}
+
+// TagNames returns the names of all the tags of the scenario, including the
+// tags inherited from the feature, rule and examples
+//
+// The names are shared by all the calls and must not be modified.
+func (s *Scenario) TagNames() []string {
+	if s.tagNames == nil {
+		s.tagNames = make([]string, 0, len(s.Pickle.Tags))
+		for _, tag := range s.Pickle.Tags {
+			s.tagNames = append(s.tagNames, tag.Name)
+		}
+	}
+	return s.tagNames
+}
+
+// Apply returns a copy of the FeatureSet with only the scenarios selected by
+// the filter
+//
+// Examples rows not selected are removed from the scenario outlines, the
+// outlines, rules and features left without any scenarios are dropped. The
+// given FeatureSet is not modified.
+func Apply(fs *object.FeatureSet, f Filter) *object.FeatureSet {
+	res := &object.FeatureSet{}
+	// A single Compiler keeps the IDs of the pickles unique across features
+	compiler := &pickles.Compiler{}
+	for i := range fs.Features {
+		if feature := applyFeature(&fs.Features[i], f, compiler); feature != nil {
+			res.Features = append(res.Features, *feature)
+		}
+	}
+	return res
+}
+
+type location struct {
+	line, column int
+}
+
+// selection is the set of scenarios and example rows selected in a feature
+type selection struct {
+	scenarios map[location]bool
+	rows      map[int]bool
+}
+
+func applyFeature(feature *object.Feature, f Filter, compiler *pickles.Compiler) *object.Feature {
+	// The pickles are matched to the scenarios they were compiled from by the
+	// location of the scenario
+	rules := map[location]*object.Rule{}
+	scenarioTypes := map[location]object.ScenarioType{}
+	addScenarios := func(rule *object.Rule, scenarios []object.ScenarioType) {
+		for _, scenarioType := range scenarios {
+			loc := scenarioLocation(scenarioType)
+			rules[loc] = rule
+			scenarioTypes[loc] = scenarioType
+		}
+	}
+	addScenarios(nil, feature.Scenarios)
+	for i := range feature.Rules {
+		addScenarios(&feature.Rules[i], feature.Rules[i].Scenarios)
+	}
+
+	selected := selection{scenarios: map[location]bool{}, rows: map[int]bool{}}
+	// The placeholders missing from the examples do not change the selection
+	compiled, _ := compiler.Compile(feature)
+	for i := range compiled {
+		pickle := &compiled[i]
+		loc := location{pickle.LineNumber, pickle.Column}
+		scenario := &Scenario{
+			Feature:      feature,
+			Rule:         rules[loc],
+			ScenarioType: scenarioTypes[loc],
+			Pickle:       pickle,
+		}
+		if f.Match(scenario) {
+			selected.scenarios[loc] = true
+			if pickle.ExampleLineNumber != 0 {
+				selected.rows[pickle.ExampleLineNumber] = true
+			}
+		}
+	}
+	if len(selected.scenarios) == 0 {
+		return nil
+	}
+
+	res := *feature
+	res.Scenarios = selected.scenarioTypes(feature.Scenarios)
+	res.Rules = nil
+	for _, rule := range feature.Rules {
+		if scenarios := selected.scenarioTypes(rule.Scenarios); len(scenarios) != 0 {
+			rule.Scenarios = scenarios
+			res.Rules = append(res.Rules, rule)
+		}
+	}
+	return &res
+}
+
+func scenarioLocation(scenarioType object.ScenarioType) location {
+	switch scenario := scenarioType.(type) {
+	case *object.Scenario:
+		return location{scenario.LineNumber, scenario.Column}
+	case *object.ScenarioOutline:
+		return location{scenario.LineNumber, scenario.Column}
+	}
+	return location{}
+}
+
+// scenarioTypes returns the selected scenarios, the scenario outlines are
+// copied with only the selected example rows
+func (s selection) scenarioTypes(scenarioTypes []object.ScenarioType) []object.ScenarioType {
+	var res []object.ScenarioType
+	for _, scenarioType := range scenarioTypes {
+		if !s.scenarios[scenarioLocation(scenarioType)] {
+			continue
+		}
+		if outline, ok := scenarioType.(*object.ScenarioOutline); ok {
+			res = append(res, s.outline(outline))
+		} else {
+			res = append(res, scenarioType)
+		}
+	}
+	return res
+}
+
+// outline copies the scenario outline with only the selected example rows,
+// the examples left without any rows are removed
+func (s selection) outline(outline *object.ScenarioOutline) *object.ScenarioOutline {
+	res := *outline
+	res.Tables = nil
+	res.TableNames = nil
+	res.TableTags = nil
+	res.TableDescriptions = nil
+	res.TableTokens = nil
+	res.TableTagTokens = nil
+	for i, table := range outline.Tables {
+		if len(table) == 0 {
+			continue
+		}
+		rows := object.Table{table[0]}
+		for _, row := range table[1:] {
+			if len(row) != 0 && s.rows[row[0].LineNumber] {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 1 {
+			continue
+		}
+		res.Tables = append(res.Tables, rows)
+		if i < len(outline.TableNames) {
+			res.TableNames = append(res.TableNames, outline.TableNames[i])
+		}
+		if i < len(outline.TableTags) {
+			res.TableTags = append(res.TableTags, outline.TableTags[i])
+		}
+		if i < len(outline.TableDescriptions) {
+			res.TableDescriptions = append(res.TableDescriptions, outline.TableDescriptions[i])
+		}
+		if i < len(outline.TableTokens) {
+			res.TableTokens = append(res.TableTokens, outline.TableTokens[i])
+		}
+		if i < len(outline.TableTagTokens) {
+			res.TableTagTokens = append(res.TableTagTokens, outline.TableTagTokens[i])
+		}
+	}
+	return &res
+}
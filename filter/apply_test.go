@@ -0,0 +1,211 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/utils"
+)
+
+const applyInput = `@feature
+Feature: filtering
+
+  Scenario: plain
+    Given a step
+
+  @outline
+  Scenario Outline: eating <food>
+    Given I eat <food>
+
+    @fruit
+    Examples:
+      | food   |
+      | apple  |
+      | banana |
+
+    @veg
+    Examples:
+      | food   |
+      | carrot |
+
+  @rule
+  Rule: a rule
+
+    @wip
+    Scenario: in rule
+      Given a step
+`
+
+func parseApplyInput(t *testing.T) *object.FeatureSet {
+	l := lexer.New(applyInput)
+	p := parser.New(l)
+	fs := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatal(p.Errors()[0].GetMessage())
+	}
+	return fs
+}
+
+// scenarioNames returns the names of the pickles left in the FeatureSet
+func scenarioNames(fs *object.FeatureSet) []string {
+	var names []string
+	for i := range fs.Features {
+		for _, scenario := range fs.Features[i].GetScenarios() {
+			names = append(names, scenario.ScenarioText)
+		}
+	}
+	return names
+}
+
+// exampleRows returns the first cell of the example rows of the outlines
+func exampleRows(fs *object.FeatureSet) []string {
+	rows := []string{}
+	for _, feature := range fs.Features {
+		for _, scenarioType := range feature.Scenarios {
+			if outline, ok := scenarioType.(*object.ScenarioOutline); ok {
+				for _, table := range outline.Tables {
+					for _, row := range table[1:] {
+						rows = append(rows, row[0].Literal)
+					}
+				}
+			}
+		}
+	}
+	return rows
+}
+
+func mustParseTagExpression(t *testing.T, expression string) *TagExpression {
+	te, err := ParseTagExpression(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return te
+}
+
+func TestApply(t *testing.T) {
+	fs := parseApplyInput(t)
+
+	testData := []struct {
+		filter        Filter
+		expectedNames []string
+		expectedRows  []string
+	}{
+		{And(), []string{"plain", "eating apple", "eating banana", "eating carrot", "in rule"}, []string{"apple", "banana", "carrot"}},
+		{Or(), nil, nil},
+		{mustParseTagExpression(t, "@feature"), []string{"plain", "eating apple", "eating banana", "eating carrot", "in rule"}, []string{"apple", "banana", "carrot"}},
+		{mustParseTagExpression(t, "@fruit"), []string{"eating apple", "eating banana"}, []string{"apple", "banana"}},
+		{mustParseTagExpression(t, "@outline and not @fruit"), []string{"eating carrot"}, []string{"carrot"}},
+		{mustParseTagExpression(t, "@rule"), []string{"in rule"}, []string{}},
+		{Not(mustParseTagExpression(t, "@wip or @outline")), []string{"plain"}, []string{}},
+		{Or(NewTagFilter("@wip"), NewTagFilter("@veg")), []string{"eating carrot", "in rule"}, []string{"carrot"}},
+		{And(NewTagFilter("@outline"), &LineFilter{LineString: "14"}), []string{"eating apple"}, []string{"apple"}},
+		{&LineFilter{LineString: "4-8"}, []string{"plain", "eating apple", "eating banana", "eating carrot"}, []string{"apple", "banana", "carrot"}},
+		{NewTagFilter("@missing"), nil, nil},
+	}
+
+	for i, tt := range testData {
+		filtered := Apply(fs, tt.filter)
+		if names := scenarioNames(filtered); !utils.AreArrayEqual(names, tt.expectedNames) {
+			t.Fatalf("Scenarios of filter %d mismatch, expected %v, got %v", i, tt.expectedNames, names)
+		}
+		if len(tt.expectedNames) == 0 && len(filtered.Features) != 0 {
+			t.Fatalf("Expected the features without scenarios to be dropped for filter %d", i)
+		}
+		if len(tt.expectedNames) == 0 {
+			continue
+		}
+		if rows := exampleRows(filtered); !utils.AreArrayEqual(rows, tt.expectedRows) {
+			t.Fatalf("Example rows of filter %d mismatch, expected %v, got %v", i, tt.expectedRows, rows)
+		}
+	}
+}
+
+func TestApplyKeepsExamplesMetadata(t *testing.T) {
+	fs := parseApplyInput(t)
+	filtered := Apply(fs, NewTagFilter("@veg"))
+
+	outline := filtered.Features[0].Scenarios[0].(*object.ScenarioOutline)
+	if len(outline.Tables) != 1 || len(outline.TableTags) != 1 || len(outline.TableTokens) != 1 {
+		t.Fatalf("Expected one examples table with its tags and token, got %v tables", len(outline.Tables))
+	}
+	if !utils.AreArrayEqual(outline.TableTags[0], []string{"veg"}) {
+		t.Fatalf("Examples tags mismatch, expected %v, got %v", []string{"veg"}, outline.TableTags[0])
+	}
+	if len(filtered.Features[0].Rules) != 0 {
+		t.Fatalf("Expected the rule without scenarios to be dropped")
+	}
+}
+
+func TestApplyLeavesFeatureSetUnchanged(t *testing.T) {
+	fs := parseApplyInput(t)
+	Apply(fs, NewTagFilter("@fruit"))
+
+	if names := scenarioNames(fs); len(names) != 5 {
+		t.Fatalf("Expected the FeatureSet to keep 5 scenarios, got %v", names)
+	}
+	if rows := exampleRows(fs); !utils.AreArrayEqual(rows, []string{"apple", "banana", "carrot"}) {
+		t.Fatalf("Expected the FeatureSet to keep all the example rows, got %v", rows)
+	}
+}
+
+// benchFeatureSet parses a feature with given number of scenarios and
+// scenario outlines with ten example rows
+func benchFeatureSet(b *testing.B, scenarios int) *object.FeatureSet {
+	var sb strings.Builder
+	sb.WriteString("@feature\nFeature: benchmark\n\n")
+	for i := 0; i < scenarios; i++ {
+		fmt.Fprintf(&sb, "  @scenario @wip\n  Scenario: scenario %d\n    Given a step\n\n", i)
+		fmt.Fprintf(&sb, "  @outline\n  Scenario Outline: outline %d <name>\n    Given I eat <name>\n\n    @examples\n    Examples:\n      | name |\n", i)
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&sb, "      | fruit %d |\n", j)
+		}
+		sb.WriteString("\n")
+	}
+	l := lexer.New(sb.String())
+	l.FilePath = "bench.feature"
+	p := parser.New(l)
+	fs := p.Parse()
+	if len(p.Errors()) != 0 {
+		b.Fatal(p.Errors()[0].GetMessage())
+	}
+	return fs
+}
+
+// benchFilters are the filters used by the benchmarks
+func benchFilters(b *testing.B) map[string]Filter {
+	expression, err := ParseTagExpression("@outline and not @wip")
+	if err != nil {
+		b.Fatal(err)
+	}
+	location, err := ParseLocationFilter("bench.feature:10:200")
+	if err != nil {
+		b.Fatal(err)
+	}
+	lines, err := ParseLineFilter("100-200")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return map[string]Filter{
+		"tags":       NewTagFilter("@outline && ~@wip"),
+		"expression": expression,
+		"name":       NewNameFilter("fruit 1"),
+		"location":   location,
+		"lines":      lines,
+	}
+}
+
+func BenchmarkApply(b *testing.B) {
+	fs := benchFeatureSet(b, 100)
+	for name, f := range benchFilters(b) {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Apply(fs, f)
+			}
+		})
+	}
+}
@@ -1,62 +1,106 @@
 package filter
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/dpakach/gorkin/object"
-	"github.com/dpakach/gorkin/utils"
 )
 
-// Filter interface is used to filter scenarios and features
+// Filter selects the scenarios of the features
+//
+// Filters are matched against every compiled scenario, so a scenario outline
+// is matched once for every row of its examples
 type Filter interface {
-	MatchFeature(feature object.Feature)
-	MatchScenario(feature object.Scenario)
+	// Match reports whether the scenario is selected by the filter
+	Match(scenario *Scenario) bool
 }
 
-// TagFilter filters scenarios and features based on tags
-type TagFilter struct {
-	filterString string
+// And returns a filter matching the scenarios matched by all the filters
+func And(filters ...Filter) Filter {
+	return andFilter(filters)
+}
+
+// Or returns a filter matching the scenarios matched by any of the filters
+func Or(filters ...Filter) Filter {
+	return orFilter(filters)
 }
 
-func (tf *TagFilter) getTagsNot() []string {
-	var tags []string
-	for _, tag := range strings.Split(tf.filterString, "&&") {
-		if tag[0] == '~' {
-			tags = append(tags, tag[2:])
+// Not returns a filter matching the scenarios not matched by the filter
+func Not(filter Filter) Filter {
+	return notFilter{filter}
+}
+
+type andFilter []Filter
+
+func (af andFilter) Match(scenario *Scenario) bool {
+	for _, filter := range af {
+		if !filter.Match(scenario) {
+			return false
 		}
 	}
-	return tags
+	return true
 }
 
-func (tf *TagFilter) getTags() []string {
-	var tags []string
-	for _, tag := range strings.Split(tf.filterString, "&&") {
-		if tag[0] != '~' {
-			tags = append(tags, tag[1:])
+type orFilter []Filter
+
+func (of orFilter) Match(scenario *Scenario) bool {
+	for _, filter := range of {
+		if filter.Match(scenario) {
+			return true
 		}
 	}
-	return tags
+	return false
 }
 
-// MatchFeature matches given feature against the tag filter
-func (tf *TagFilter) MatchFeature(feature object.Feature) bool {
-	return tf.tagsMatchPresent(feature.Tags)
+type notFilter struct {
+	filter Filter
 }
 
-// MatchScenario matches given Scenario against the tag filter
-func (tf *TagFilter) MatchScenario(scenario object.ScenarioType) bool {
-	return tf.tagsMatchPresent(scenario.GetTags())
+func (nf notFilter) Match(scenario *Scenario) bool {
+	return !nf.filter.Match(scenario)
 }
 
-func (tf *TagFilter) tagsMatchPresent(tags []string) bool {
-	if utils.AreArrayEqual(tags, []string{}) && !utils.AreArrayEqual(tf.getTags(), []string{}) {
-		return false
+// TagFilter filters scenarios based on tags
+//
+// The filter string is a list of tags joined with "&&", the tags prefixed
+// with "~" must not be present, see ParseTagExpression for the full tag
+// expressions
+type TagFilter struct {
+	// tags and tagsNot are split from the filter string once
+	tags    []string
+	tagsNot []string
+}
+
+// NewTagFilter creates a TagFilter from given filter string
+func NewTagFilter(filterString string) *TagFilter {
+	tf := &TagFilter{}
+	for _, tag := range strings.Split(filterString, "&&") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "~") {
+			tf.tagsNot = append(tf.tagsNot, strings.TrimPrefix(tag[1:], "@"))
+		} else if tag != "" {
+			tf.tags = append(tf.tags, strings.TrimPrefix(tag, "@"))
+		}
 	}
-	for _, tag := range tags {
-		if !tf.tagMatchPresent(tag) {
+	return tf
+}
+
+// Match matches the tags of given scenario against the tag filter
+func (tf *TagFilter) Match(scenario *Scenario) bool {
+	return tf.tagsMatchPresent(scenario.TagNames())
+}
+
+// tagsMatchPresent reports whether all the wanted tags are present and none
+// of the unwanted tags are
+func (tf *TagFilter) tagsMatchPresent(tags []string) bool {
+	for _, tag := range tf.tags {
+		if !containsTag(tags, tag) {
 			return false
 		}
+	}
+	for _, tag := range tags {
 		if tf.tagMatchNotPresent(tag) {
 			return false
 		}
@@ -65,16 +109,11 @@ func (tf *TagFilter) tagsMatchPresent(tags []string) bool {
 }
 
 func (tf *TagFilter) tagMatchNotPresent(find string) bool {
-	for _, tag := range tf.getTagsNot() {
-		if tag == find {
-			return true
-		}
-	}
-	return false
+	return containsTag(tf.tagsNot, find)
 }
 
-func (tf *TagFilter) tagMatchPresent(find string) bool {
-	for _, tag := range tf.getTags() {
+func containsTag(tags []string, find string) bool {
+	for _, tag := range tags {
 		if tag == find {
 			return true
 		}
@@ -83,59 +122,66 @@ func (tf *TagFilter) tagMatchPresent(find string) bool {
 }
 
 // LineFilter creates a filter based on line numbers
+//
+// LineString is either a single line number or a range of lines like "2-5",
+// use ParseLineFilter to validate it up front
 type LineFilter struct {
 	LineString string
+
+	// start and end are the parsed range when the filter was created by
+	// ParseLineFilter
+	parsed     bool
+	start, end int
 }
 
-func (lf *LineFilter) getStart() int {
-	var startString string
-	if strings.Contains(lf.LineString, "-") {
-		startString = strings.Split(lf.LineString, "-")[0]
-		if len(strings.Split(lf.LineString, "-")) > 2 {
-			panic("Invalid filter string provided")
-		}
-	} else {
-		startString = lf.LineString
-	}
-	start, err := strconv.Atoi(startString)
+// ParseLineFilter creates a LineFilter from given line number or line range
+func ParseLineFilter(lineString string) (*LineFilter, error) {
+	lf := &LineFilter{LineString: lineString}
+	start, end, err := lf.getRange()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return start
+	lf.parsed, lf.start, lf.end = true, start, end
+	return lf, nil
 }
 
-func (lf *LineFilter) getEnd() int {
-	var endString string
-	if strings.Contains(lf.LineString, "-") {
-		arr := strings.Split(lf.LineString, "-")
-		endString = arr[len(arr)-1]
-		if len(arr) > 2 {
-			panic("Invalid filter string provided")
-		}
-	} else {
-		endString = lf.LineString
+func (lf *LineFilter) getRange() (int, int, error) {
+	arr := strings.Split(lf.LineString, "-")
+	if len(arr) > 2 {
+		return 0, 0, fmt.Errorf("invalid line filter %q", lf.LineString)
 	}
-	end, err := strconv.Atoi(endString)
+	start, err := strconv.Atoi(strings.TrimSpace(arr[0]))
 	if err != nil {
-		panic(err)
+		return 0, 0, fmt.Errorf("invalid line filter %q", lf.LineString)
 	}
-	return end
+	end, err := strconv.Atoi(strings.TrimSpace(arr[len(arr)-1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line filter %q", lf.LineString)
+	}
+	return start, end, nil
+}
+
+func (lf *LineFilter) matchLine(ln int) bool {
+	start, end := lf.start, lf.end
+	if !lf.parsed {
+		var err error
+		if start, end, err = lf.getRange(); err != nil {
+			return false
+		}
+	}
+	return ln >= start && ln <= end
 }
 
 // MatchFeature matches given feature against the line filter
 func (lf *LineFilter) MatchFeature(feature *object.Feature) bool {
-	ln := feature.Token.LineNumber
-	if ln >= lf.getStart() && ln <= lf.getEnd() {
-		return true
-	}
-	return false
+	return lf.matchLine(feature.Token.LineNumber)
 }
 
-// MatchScenario matches given Scenario against the Line filter
-func (lf *LineFilter) MatchScenario(scenario *object.Scenario) bool {
-	ln := scenario.LineNumber
-	if ln >= lf.getStart() && ln <= lf.getEnd() {
+// Match matches the line of given scenario, or of its example row, against
+// the line filter
+func (lf *LineFilter) Match(scenario *Scenario) bool {
+	if lf.matchLine(scenario.Pickle.LineNumber) {
 		return true
 	}
-	return false
+	return scenario.Pickle.ExampleLineNumber != 0 && lf.matchLine(scenario.Pickle.ExampleLineNumber)
 }
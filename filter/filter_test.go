@@ -3,6 +3,7 @@ package filter
 import (
 	"github.com/dpakach/gorkin/lexer"
 	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/pickles"
 	"github.com/dpakach/gorkin/utils"
 	"testing"
 )
@@ -29,14 +30,14 @@ func TestLineFilterMatchFeature(t *testing.T) {
 		{"100", false},
 		{"1-100", true},
 		{"2-5", true},
-		//{"addd", false},
-		//{"1-200-300", false},
+		{"addd", false},
+		{"1-200-300", false},
 		{"4", true},
 		{"5", false},
 	}
 
 	for _, tt := range testData {
-		lf := &LineFilter{tt.input}
+		lf := &LineFilter{LineString: tt.input}
 		match := lf.MatchFeature(feature)
 		if match != tt.expectedMatch {
 			t.Fatalf("Match feature for %q incorrect, expected: %v, got: %v", tt.input, tt.expectedMatch, match)
@@ -54,12 +55,14 @@ func TestGetTags(t *testing.T) {
 		{"@tag1&&@tag2&&@tag3", []string{"tag1", "tag2", "tag3"}, []string{}},
 		{"~@tag1&&~@tag2&&~@tag3", []string{}, []string{"tag1", "tag2", "tag3"}},
 		{"~@tag1&&@tag2&&~@tag3", []string{"tag2"}, []string{"tag1", "tag3"}},
+		{"", []string{}, []string{}},
+		{"@tag1&&&&~@tag2&&", []string{"tag1"}, []string{"tag2"}},
 	}
 
 	for _, tt := range testdata {
-		filter := &TagFilter{tt.input}
-		tags := filter.getTags()
-		tagsNot := filter.getTagsNot()
+		filter := NewTagFilter(tt.input)
+		tags := filter.tags
+		tagsNot := filter.tagsNot
 
 		if !utils.AreArrayEqual(tags, tt.expectedTags) {
 			t.Fatalf("Tags are not equal, expected: %v, got: %v", tt.expectedTags, tags)
@@ -89,13 +92,60 @@ func TestTagMatchPresent(t *testing.T) {
 		{"@tag1", []string{}, false},
 		{"~@tag1", []string{"tag1"}, false},
 		{"@tag1", []string{"tag2"}, false},
+		{"@tag1", []string{"tag1", "tag2"}, true},
+		{"~@tag1", []string{"tag2"}, true},
 	}
 
 	for _, tt := range testdata {
-		filter := TagFilter{tt.input}
+		filter := NewTagFilter(tt.input)
 
 		if filter.tagsMatchPresent(tt.inputTags) != tt.match {
 			t.Fatalf("Invalid match for filter %q, expected: %v, got: %v", tt.input, tt.match, filter.tagsMatchPresent(tt.inputTags))
 		}
 	}
 }
+
+func TestParseLineFilter(t *testing.T) {
+	testData := []struct {
+		input         string
+		expectedError bool
+	}{
+		{"4", false},
+		{"2-5", false},
+		{"", true},
+		{"-", true},
+		{"addd", true},
+		{"1-", true},
+		{"1-200-300", true},
+	}
+
+	for _, tt := range testData {
+		lf, err := ParseLineFilter(tt.input)
+		if (err != nil) != tt.expectedError {
+			t.Fatalf("Parse error for %q incorrect, expected error: %v, got: %v", tt.input, tt.expectedError, err)
+		}
+		if err == nil && lf.LineString != tt.input {
+			t.Fatalf("Line string mismatch, expected %v, got %v", tt.input, lf.LineString)
+		}
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	fs := benchFeatureSet(b, 100)
+	feature := &fs.Features[0]
+	var scenarios []*Scenario
+	compiled, _ := pickles.Compile(feature)
+	for i := range compiled {
+		scenarios = append(scenarios, &Scenario{Feature: feature, Pickle: &compiled[i]})
+	}
+	for name, f := range benchFilters(b) {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, scenario := range scenarios {
+					f.Match(scenario)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package filter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/token"
+)
+
+// Location is a feature file and the lines selected in it, a Location
+// without any lines selects the whole file
+type Location struct {
+	Path  string
+	Lines []int
+}
+
+// ParseLocation parses a location like "path/to/file.feature:12:40"
+func ParseLocation(selector string) (Location, error) {
+	parts := strings.Split(selector, ":")
+	var lines []int
+	// The lines are the numbers at the end, the rest is the path which may
+	// have colons of its own
+	for len(parts) > 1 {
+		line, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		if line < 1 {
+			return Location{}, fmt.Errorf("invalid location %q: line %d is not a positive number", selector, line)
+		}
+		lines = append([]int{line}, lines...)
+		parts = parts[:len(parts)-1]
+	}
+	path := strings.Join(parts, ":")
+	if path == "" {
+		return Location{}, fmt.Errorf("invalid location %q: the path is empty", selector)
+	}
+	return Location{Path: path, Lines: lines}, nil
+}
+
+// LocationFilter selects the scenarios at given locations
+//
+// A line anywhere in a scenario, from its tags to the line before the next
+// scenario or to its last step for the last scenario, selects the scenario.
+// A line on an example row selects only the scenario compiled from that row,
+// the lines of the Examples keyword, tags and header select all the rows of
+// the examples. The lines of the feature and rule headers select all their
+// scenarios.
+type LocationFilter struct {
+	Locations []Location
+}
+
+// ParseLocationFilter creates a LocationFilter from the location selectors,
+// see ParseLocation
+func ParseLocationFilter(selectors ...string) (*LocationFilter, error) {
+	lf := &LocationFilter{}
+	for _, selector := range selectors {
+		location, err := ParseLocation(selector)
+		if err != nil {
+			return nil, err
+		}
+		lf.Locations = append(lf.Locations, location)
+	}
+	return lf, nil
+}
+
+// Match reports whether the scenario is at one of the locations
+func (lf *LocationFilter) Match(scenario *Scenario) bool {
+	for _, location := range lf.Locations {
+		if !samePath(location.Path, scenario.Feature.URI) {
+			continue
+		}
+		if len(location.Lines) == 0 {
+			return true
+		}
+		for _, line := range location.Lines {
+			if matchLocation(scenario, line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// samePath reports whether both the paths point to the same file
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+func matchLocation(scenario *Scenario, line int) bool {
+	feature := scenario.Feature
+	if line >= tagsStart(feature.TagTokens, feature.Token.LineNumber) && line <= feature.Token.LineNumber {
+		return true
+	}
+	if rule := scenario.Rule; rule != nil && line >= tagsStart(rule.TagTokens, rule.Token.LineNumber) && line <= rule.Token.LineNumber {
+		return true
+	}
+
+	start := scenario.Pickle.LineNumber
+	var outline *object.ScenarioOutline
+	switch st := scenario.ScenarioType.(type) {
+	case *object.Scenario:
+		start = tagsStart(st.TagTokens, st.LineNumber)
+	case *object.ScenarioOutline:
+		start = tagsStart(st.TagTokens, st.LineNumber)
+		outline = st
+	}
+	next, ok := nextBlockStart(feature, start)
+	if line < start || (ok && line >= next) || (!ok && line > lastLine(scenario.ScenarioType)) {
+		return false
+	}
+	if outline == nil {
+		return true
+	}
+
+	for i, table := range outline.Tables {
+		if len(table) == 0 {
+			continue
+		}
+		examplesStart := table[0][0].LineNumber
+		if i < len(outline.TableTokens) {
+			examplesStart = outline.TableTokens[i].LineNumber
+		}
+		if i < len(outline.TableTagTokens) {
+			examplesStart = tagsStart(outline.TableTagTokens[i], examplesStart)
+		}
+		lastRow := table[len(table)-1]
+		if line < examplesStart || len(lastRow) == 0 || line > lastRow[0].LineNumber {
+			continue
+		}
+		if line > table[0][0].LineNumber {
+			return line == scenario.Pickle.ExampleLineNumber
+		}
+		for _, row := range table[1:] {
+			if len(row) != 0 && row[0].LineNumber == scenario.Pickle.ExampleLineNumber {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// tagsStart returns the line of the first tag, or given line when there are
+// no tags before it
+func tagsStart(tags []token.Token, line int) int {
+	if len(tags) > 0 && tags[0].LineNumber < line {
+		return tags[0].LineNumber
+	}
+	return line
+}
+
+// nextBlockStart returns the first line of the background, scenario or rule
+// following given line in the feature, ok is false when there is none
+func nextBlockStart(feature *object.Feature, line int) (next int, ok bool) {
+	consider := func(start int) {
+		if start > line && (!ok || start < next) {
+			next, ok = start, true
+		}
+	}
+	considerScenarios := func(scenarios []object.ScenarioType) {
+		for _, scenarioType := range scenarios {
+			switch st := scenarioType.(type) {
+			case *object.Scenario:
+				consider(tagsStart(st.TagTokens, st.LineNumber))
+			case *object.ScenarioOutline:
+				consider(tagsStart(st.TagTokens, st.LineNumber))
+			}
+		}
+	}
+
+	if feature.Background != nil {
+		consider(feature.Background.LineNumber)
+	}
+	considerScenarios(feature.Scenarios)
+	for _, rule := range feature.Rules {
+		consider(tagsStart(rule.TagTokens, rule.Token.LineNumber))
+		if rule.Background != nil {
+			consider(rule.Background.LineNumber)
+		}
+		considerScenarios(rule.Scenarios)
+	}
+	return next, ok
+}
+
+// lastLine returns the last line of the steps and examples of the scenario
+func lastLine(scenarioType object.ScenarioType) int {
+	last := 0
+	var steps []object.Step
+	switch st := scenarioType.(type) {
+	case *object.Scenario:
+		last, steps = st.LineNumber, st.Steps
+	case *object.ScenarioOutline:
+		last, steps = st.LineNumber, st.Steps
+		for _, table := range st.Tables {
+			if len(table) != 0 && len(table[len(table)-1]) != 0 {
+				last = table[len(table)-1][0].LineNumber
+			}
+		}
+	}
+	for _, step := range steps {
+		if step.LineNumber > last {
+			last = step.LineNumber
+		}
+		if len(step.Table) != 0 && len(step.Table[len(step.Table)-1]) != 0 && step.Table[len(step.Table)-1][0].LineNumber > last {
+			last = step.Table[len(step.Table)-1][0].LineNumber
+		}
+		if step.DocString != nil {
+			// The content lines and the closing fence follow the opening one
+			end := step.DocString.LineNumber + 1
+			if step.DocString.Content != "" {
+				end += strings.Count(step.DocString.Content, "\n") + 1
+			}
+			if end > last {
+				last = end
+			}
+		}
+	}
+	return last
+}
@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/utils"
+)
+
+// pickleRows returns the names of the pickles of the FeatureSet along with
+// the first cell of their example rows
+func pickleRows(fs *object.FeatureSet) []string {
+	var res []string
+	for _, feature := range fs.Features {
+		var scenarios []object.ScenarioType
+		scenarios = append(scenarios, feature.Scenarios...)
+		for _, rule := range feature.Rules {
+			scenarios = append(scenarios, rule.Scenarios...)
+		}
+		for _, scenarioType := range scenarios {
+			switch scenario := scenarioType.(type) {
+			case *object.Scenario:
+				res = append(res, feature.URI+" "+scenario.ScenarioText)
+			case *object.ScenarioOutline:
+				for _, table := range scenario.Tables {
+					for _, row := range table[1:] {
+						res = append(res, feature.URI+" "+row[0].Literal)
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+func TestLocationFilter(t *testing.T) {
+	fs := parseApplyInput(t)
+	fs.Features[0].URI = "features/a.feature"
+	other := parseApplyInput(t)
+	other.Features[0].URI = "features/b.feature"
+	fs.Merge(other)
+
+	all := []string{"features/a.feature plain", "features/a.feature apple", "features/a.feature banana", "features/a.feature carrot", "features/a.feature in rule"}
+	testData := []struct {
+		selectors []string
+		expected  []string
+	}{
+		{[]string{"features/a.feature"}, all},
+		{[]string{"features/a.feature:1"}, all},
+		{[]string{"features/a.feature:2"}, all},
+		{[]string{"features/a.feature:4"}, []string{"features/a.feature plain"}},
+		{[]string{"features/a.feature:5"}, []string{"features/a.feature plain"}},
+		{[]string{"features/a.feature:6"}, []string{"features/a.feature plain"}},
+		{[]string{"features/a.feature:7"}, []string{"features/a.feature apple", "features/a.feature banana", "features/a.feature carrot"}},
+		{[]string{"features/a.feature:9"}, []string{"features/a.feature apple", "features/a.feature banana", "features/a.feature carrot"}},
+		{[]string{"features/a.feature:11"}, []string{"features/a.feature apple", "features/a.feature banana"}},
+		{[]string{"features/a.feature:13"}, []string{"features/a.feature apple", "features/a.feature banana"}},
+		{[]string{"features/a.feature:14"}, []string{"features/a.feature apple"}},
+		{[]string{"features/a.feature:15"}, []string{"features/a.feature banana"}},
+		{[]string{"features/a.feature:20"}, []string{"features/a.feature carrot"}},
+		{[]string{"features/a.feature:14:20"}, []string{"features/a.feature apple", "features/a.feature carrot"}},
+		{[]string{"features/a.feature:22"}, []string{"features/a.feature in rule"}},
+		{[]string{"features/a.feature:27"}, []string{"features/a.feature in rule"}},
+		{[]string{"./features/../features/a.feature:5"}, []string{"features/a.feature plain"}},
+		{[]string{"features/a.feature:5", "features/b.feature:15"}, []string{"features/a.feature plain", "features/b.feature banana"}},
+		{[]string{"features/c.feature:5"}, nil},
+		{[]string{"features/a.feature:100"}, nil},
+	}
+
+	for _, tt := range testData {
+		lf, err := ParseLocationFilter(tt.selectors...)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", tt.selectors, err)
+		}
+		if rows := pickleRows(Apply(fs, lf)); !utils.AreArrayEqual(rows, tt.expected) {
+			t.Fatalf("Scenarios of %v mismatch, expected %v, got %v", tt.selectors, tt.expected, rows)
+		}
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	testData := []struct {
+		input         string
+		expectedPath  string
+		expectedLines []int
+		expectedError bool
+	}{
+		{"a.feature", "a.feature", nil, false},
+		{"a.feature:12", "a.feature", []int{12}, false},
+		{"dir/a.feature:12:40", "dir/a.feature", []int{12, 40}, false},
+		{"C:/dir/a.feature:3", "C:/dir/a.feature", []int{3}, false},
+		{"a:b.feature", "a:b.feature", nil, false},
+		{"a.feature:0", "", nil, true},
+		{"a.feature:-1", "", nil, true},
+		{":12", "", nil, true},
+		{"", "", nil, true},
+	}
+
+	for _, tt := range testData {
+		location, err := ParseLocation(tt.input)
+		if (err != nil) != tt.expectedError {
+			t.Fatalf("Parse error for %q incorrect, expected error: %v, got: %v", tt.input, tt.expectedError, err)
+		}
+		if err != nil {
+			continue
+		}
+		if location.Path != tt.expectedPath {
+			t.Fatalf("Path of %q mismatch, expected %v, got %v", tt.input, tt.expectedPath, location.Path)
+		}
+		if len(location.Lines) != len(tt.expectedLines) {
+			t.Fatalf("Lines of %q mismatch, expected %v, got %v", tt.input, tt.expectedLines, location.Lines)
+		}
+		for i := range location.Lines {
+			if location.Lines[i] != tt.expectedLines[i] {
+				t.Fatalf("Lines of %q mismatch, expected %v, got %v", tt.input, tt.expectedLines, location.Lines)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NameFilter selects the scenarios whose feature title, rule title or
+// scenario name matches the pattern
+//
+// The names of the scenarios compiled from scenario outlines have the
+// placeholders substituted with the values of their example row.
+type NameFilter struct {
+	Pattern *regexp.Regexp
+}
+
+// NewNameFilter creates a NameFilter matching the names containing given
+// text, ignoring the case
+func NewNameFilter(substring string) *NameFilter {
+	return &NameFilter{Pattern: regexp.MustCompile("(?i)" + regexp.QuoteMeta(substring))}
+}
+
+// NewNameRegexpFilter creates a NameFilter matching the names against given
+// regular expression
+func NewNameRegexpFilter(expr string) (*NameFilter, error) {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name filter %q: %v", expr, err)
+	}
+	return &NameFilter{Pattern: pattern}, nil
+}
+
+// ParseNameFilter creates a NameFilter from a name selector, a selector
+// between slashes like "/^checkout/" is a regular expression and anything
+// else is a case-insensitive substring
+func ParseNameFilter(selector string) (*NameFilter, error) {
+	if len(selector) > 1 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/") {
+		return NewNameRegexpFilter(selector[1 : len(selector)-1])
+	}
+	return NewNameFilter(selector), nil
+}
+
+// Match reports whether any of the names of the scenario matches the pattern
+func (nf *NameFilter) Match(scenario *Scenario) bool {
+	if nf.Pattern.MatchString(scenario.Feature.Title) || nf.Pattern.MatchString(scenario.Pickle.Name) {
+		return true
+	}
+	return scenario.Rule != nil && nf.Pattern.MatchString(scenario.Rule.Title)
+}
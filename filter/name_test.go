@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/dpakach/gorkin/utils"
+)
+
+func TestNameFilter(t *testing.T) {
+	fs := parseApplyInput(t)
+
+	testData := []struct {
+		selector string
+		expected []string
+	}{
+		{"plain", []string{" plain"}},
+		{"PLAIN", []string{" plain"}},
+		{"filtering", []string{" plain", " apple", " banana", " carrot", " in rule"}},
+		{"a rule", []string{" in rule"}},
+		{"eating banana", []string{" banana"}},
+		{"eating <food>", nil},
+		{"/^eating (apple|carrot)$/", []string{" apple", " carrot"}},
+		{"/^Plain$/", nil},
+		{"/(?i)^Plain$/", []string{" plain"}},
+		{"a.b", nil},
+		{"missing", nil},
+	}
+
+	for _, tt := range testData {
+		nf, err := ParseNameFilter(tt.selector)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.selector, err)
+		}
+		if rows := pickleRows(Apply(fs, nf)); !utils.AreArrayEqual(rows, tt.expected) {
+			t.Fatalf("Scenarios of %q mismatch, expected %v, got %v", tt.selector, tt.expected, rows)
+		}
+	}
+}
+
+func TestNameFilterComposes(t *testing.T) {
+	fs := parseApplyInput(t)
+
+	f := And(Or(NewNameFilter("apple"), NewNameFilter("carrot"), NewNameFilter("rule")), Not(NewTagFilter("@wip")))
+	expected := []string{" apple", " carrot"}
+	if rows := pickleRows(Apply(fs, f)); !utils.AreArrayEqual(rows, expected) {
+		t.Fatalf("Scenarios mismatch, expected %v, got %v", expected, rows)
+	}
+}
+
+func TestParseNameFilterErrors(t *testing.T) {
+	for _, selector := range []string{"/(/", "/[a-/"} {
+		if _, err := ParseNameFilter(selector); err == nil {
+			t.Fatalf("Expected an error for %q", selector)
+		}
+	}
+	if nf, err := ParseNameFilter("/"); err != nil || !nf.Pattern.MatchString("a/b") {
+		t.Fatalf("Expected %q to be a substring filter", "/")
+	}
+}
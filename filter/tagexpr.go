@@ -0,0 +1,336 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TagExpression is a parsed Cucumber tag expression like
+// "@smoke and not (@wip or @flaky)"
+//
+// The operators in the order of their precedence are "not", "and" and "or",
+// parentheses group the sub-expressions. Whitespace, parentheses and
+// backslashes in the tags are escaped with a backslash.
+type TagExpression struct {
+	// Expression is the root of the expression tree, nil for an empty
+	// expression which matches everything
+	Expression TagNode
+}
+
+// TagNode is a node in the tree of a tag expression
+type TagNode interface {
+	// String returns the node as a tag expression
+	String() string
+	evaluate(tags map[string]bool) bool
+	precedence() int
+}
+
+// TagLiteral is a single tag in the expression
+type TagLiteral struct {
+	Name string
+}
+
+// TagNot negates the operand
+type TagNot struct {
+	Operand TagNode
+}
+
+// TagAnd matches when both operands match
+type TagAnd struct {
+	Left, Right TagNode
+}
+
+// TagOr matches when any of the operands match
+type TagOr struct {
+	Left, Right TagNode
+}
+
+// TagExpressionError is returned for the expressions with a syntax error
+type TagExpressionError struct {
+	Expression string
+	// Column is the 1-based position of the error in the expression
+	Column  int
+	Message string
+}
+
+func (e *TagExpressionError) Error() string {
+	return fmt.Sprintf("invalid tag expression %q at column %d: %s", e.Expression, e.Column, e.Message)
+}
+
+// ParseTagExpression parses the tag expression
+func ParseTagExpression(expression string) (*TagExpression, error) {
+	tokens, err := tokenizeTagExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &tagParser{expression: expression, tokens: tokens}
+	if p.peek().kind == tagEOF {
+		return &TagExpression{}, nil
+	}
+	root, err := p.parseExpression(orPrecedence)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tagEOF {
+		return nil, p.errorf(tok, "unexpected %v", tok)
+	}
+	return &TagExpression{Expression: root}, nil
+}
+
+// Evaluate reports whether the tags satisfy the expression, the tags may be
+// given with or without the leading "@"
+func (te *TagExpression) Evaluate(tags []string) bool {
+	if te.Expression == nil {
+		return true
+	}
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[strings.TrimPrefix(tag, "@")] = true
+	}
+	return te.Expression.evaluate(set)
+}
+
+// Match matches the tags of given scenario against the expression
+func (te *TagExpression) Match(scenario *Scenario) bool {
+	return te.Evaluate(scenario.TagNames())
+}
+
+// String returns the expression with only the parentheses it needs, parsing
+// the result gives back the same expression
+func (te *TagExpression) String() string {
+	if te.Expression == nil {
+		return ""
+	}
+	return te.Expression.String()
+}
+
+const (
+	orPrecedence = iota
+	andPrecedence
+	notPrecedence
+)
+
+func (t *TagLiteral) String() string {
+	var out strings.Builder
+	for _, r := range t.Name {
+		if r == '\\' || r == '(' || r == ')' || unicode.IsSpace(r) {
+			out.WriteRune('\\')
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func (t *TagLiteral) evaluate(tags map[string]bool) bool {
+	return tags[strings.TrimPrefix(t.Name, "@")]
+}
+
+func (t *TagLiteral) precedence() int { return notPrecedence }
+
+func (t *TagNot) String() string {
+	if t.Operand.precedence() < notPrecedence {
+		return "not (" + t.Operand.String() + ")"
+	}
+	return "not " + t.Operand.String()
+}
+
+func (t *TagNot) evaluate(tags map[string]bool) bool {
+	return !t.Operand.evaluate(tags)
+}
+
+func (t *TagNot) precedence() int { return notPrecedence }
+
+func (t *TagAnd) String() string {
+	return binaryString(t, "and", t.Left, t.Right)
+}
+
+func (t *TagAnd) evaluate(tags map[string]bool) bool {
+	return t.Left.evaluate(tags) && t.Right.evaluate(tags)
+}
+
+func (t *TagAnd) precedence() int { return andPrecedence }
+
+func (t *TagOr) String() string {
+	return binaryString(t, "or", t.Left, t.Right)
+}
+
+func (t *TagOr) evaluate(tags map[string]bool) bool {
+	return t.Left.evaluate(tags) || t.Right.evaluate(tags)
+}
+
+func (t *TagOr) precedence() int { return orPrecedence }
+
+// binaryString joins the operands with the operator, the operators are left
+// associative so the right operand of the same precedence needs parentheses
+func binaryString(node TagNode, operator string, left, right TagNode) string {
+	l, r := left.String(), right.String()
+	if left.precedence() < node.precedence() {
+		l = "(" + l + ")"
+	}
+	if right.precedence() <= node.precedence() {
+		r = "(" + r + ")"
+	}
+	return l + " " + operator + " " + r
+}
+
+type tagTokenKind int
+
+const (
+	tagEOF tagTokenKind = iota
+	tagName
+	tagAnd
+	tagOr
+	tagNot
+	tagLeftParen
+	tagRightParen
+)
+
+type tagToken struct {
+	kind   tagTokenKind
+	text   string
+	column int
+}
+
+func (t tagToken) String() string {
+	if t.kind == tagEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenizeTagExpression splits the expression into the operators, the
+// parentheses and the tags
+func tokenizeTagExpression(expression string) ([]tagToken, error) {
+	var tokens []tagToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tagToken{tagLeftParen, "(", i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, tagToken{tagRightParen, ")", i + 1})
+			i++
+		default:
+			start := i
+			escaped := false
+			var name strings.Builder
+			for ; i < len(runes); i++ {
+				r := runes[i]
+				if r == '\\' {
+					if i+1 == len(runes) {
+						return nil, &TagExpressionError{expression, i + 1, "unterminated escape at the end of the expression"}
+					}
+					next := runes[i+1]
+					if next != '\\' && next != '(' && next != ')' && !unicode.IsSpace(next) {
+						return nil, &TagExpressionError{expression, i + 1, fmt.Sprintf("illegal escape before %q", next)}
+					}
+					name.WriteRune(next)
+					escaped = true
+					i++
+					continue
+				}
+				if r == '(' || r == ')' || unicode.IsSpace(r) {
+					break
+				}
+				name.WriteRune(r)
+			}
+			tok := tagToken{tagName, name.String(), start + 1}
+			if !escaped {
+				switch tok.text {
+				case "and":
+					tok.kind = tagAnd
+				case "or":
+					tok.kind = tagOr
+				case "not":
+					tok.kind = tagNot
+				}
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return append(tokens, tagToken{tagEOF, "", len(runes) + 1}), nil
+}
+
+// tagParser is a precedence climbing parser over the tokens of an expression
+type tagParser struct {
+	expression string
+	tokens     []tagToken
+}
+
+func (p *tagParser) peek() tagToken {
+	return p.tokens[0]
+}
+
+func (p *tagParser) next() tagToken {
+	tok := p.tokens[0]
+	if tok.kind != tagEOF {
+		p.tokens = p.tokens[1:]
+	}
+	return tok
+}
+
+func (p *tagParser) errorf(tok tagToken, format string, a ...interface{}) error {
+	return &TagExpressionError{p.expression, tok.column, fmt.Sprintf(format, a...)}
+}
+
+// parseExpression parses the binary operators with at least given precedence
+func (p *tagParser) parseExpression(minPrecedence int) (TagNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var precedence int
+		switch p.peek().kind {
+		case tagOr:
+			precedence = orPrecedence
+		case tagAnd:
+			precedence = andPrecedence
+		default:
+			return left, nil
+		}
+		if precedence < minPrecedence {
+			return left, nil
+		}
+		operator := p.next()
+		right, err := p.parseExpression(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		if operator.kind == tagOr {
+			left = &TagOr{Left: left, Right: right}
+		} else {
+			left = &TagAnd{Left: left, Right: right}
+		}
+	}
+}
+
+// parseOperand parses a tag, a negation or a parenthesized expression
+func (p *tagParser) parseOperand() (TagNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tagName:
+		return &TagLiteral{Name: tok.text}, nil
+	case tagNot:
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &TagNot{Operand: operand}, nil
+	case tagLeftParen:
+		expression, err := p.parseExpression(orPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tagRightParen {
+			return nil, p.errorf(closing, "expected \")\" to close \"(\" at column %d but got %v", tok.column, closing)
+		}
+		return expression, nil
+	default:
+		return nil, p.errorf(tok, "expected a tag, \"not\" or \"(\" but got %v", tok)
+	}
+}
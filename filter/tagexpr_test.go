@@ -0,0 +1,153 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	testData := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"@a", "@a"},
+		{"  @a  ", "@a"},
+		{"not @a", "not @a"},
+		{"@a and @b", "@a and @b"},
+		{"@a or @b and @c", "@a or @b and @c"},
+		{"(@a or @b) and @c", "(@a or @b) and @c"},
+		{"@a and (@b and @c)", "@a and (@b and @c)"},
+		{"(@a and @b) and @c", "@a and @b and @c"},
+		{"not (@a or @b)", "not (@a or @b)"},
+		{"not not @a", "not not @a"},
+		{"not @a and @b", "not @a and @b"},
+		{"@smoke and not (@wip or @flaky)", "@smoke and not (@wip or @flaky)"},
+		{"((@a))", "@a"},
+		{"@a\\ b or @c\\(d\\)", "@a\\ b or @c\\(d\\)"},
+		{"@a\\\\b", "@a\\\\b"},
+		{"@a\tand\n@b", "@a and @b"},
+		{"(@a)and(@b)", "@a and @b"},
+	}
+
+	for _, tt := range testData {
+		expression, err := ParseTagExpression(tt.input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+		}
+		if expression.String() != tt.expected {
+			t.Fatalf("Expression for %q mismatch, expected %q, got %q", tt.input, tt.expected, expression.String())
+		}
+
+		// Parsing the printed expression gives the same expression
+		again, err := ParseTagExpression(expression.String())
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", expression.String(), err)
+		}
+		if again.String() != expression.String() {
+			t.Fatalf("Expression for %q mismatch, expected %q, got %q", expression.String(), expression.String(), again.String())
+		}
+	}
+}
+
+func TestParseTagExpressionTree(t *testing.T) {
+	expression, err := ParseTagExpression("@a or not @b and @c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := expression.Expression.(*TagOr)
+	if !ok {
+		t.Fatalf("Expected the root to be *TagOr but got %T", expression.Expression)
+	}
+	if literal, ok := or.Left.(*TagLiteral); !ok || literal.Name != "@a" {
+		t.Fatalf("Expected the left operand to be @a but got %v", or.Left)
+	}
+	and, ok := or.Right.(*TagAnd)
+	if !ok {
+		t.Fatalf("Expected the right operand to be *TagAnd but got %T", or.Right)
+	}
+	if _, ok := and.Left.(*TagNot); !ok {
+		t.Fatalf("Expected *TagNot but got %T", and.Left)
+	}
+
+	expression, err = ParseTagExpression("@a\\ b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if literal := expression.Expression.(*TagLiteral); literal.Name != "@a b" {
+		t.Fatalf("Tag name mismatch, expected %q, got %q", "@a b", literal.Name)
+	}
+}
+
+func TestParseTagExpressionErrors(t *testing.T) {
+	testData := []struct {
+		input   string
+		column  int
+		message string
+	}{
+		{"@a and", 7, "expected a tag"},
+		{"and @a", 1, "expected a tag"},
+		{"@a @b", 4, "unexpected \"@b\""},
+		{"(@a", 4, "expected \")\" to close \"(\" at column 1"},
+		{"@a)", 3, "unexpected \")\""},
+		{"()", 2, "expected a tag"},
+		{"not", 4, "expected a tag"},
+		{"@a or or @b", 7, "expected a tag"},
+		{"@a\\b", 3, "illegal escape before 'b'"},
+		{"@a\\", 3, "unterminated escape"},
+	}
+
+	for _, tt := range testData {
+		_, err := ParseTagExpression(tt.input)
+		if err == nil {
+			t.Fatalf("Expected an error for %q", tt.input)
+		}
+		exprErr, ok := err.(*TagExpressionError)
+		if !ok {
+			t.Fatalf("Expected *TagExpressionError for %q but got %T", tt.input, err)
+		}
+		if exprErr.Column != tt.column {
+			t.Fatalf("Error column for %q mismatch, expected %v, got %v", tt.input, tt.column, exprErr.Column)
+		}
+		if !strings.Contains(err.Error(), tt.message) {
+			t.Fatalf("Error for %q mismatch, expected it to contain %q, got %q", tt.input, tt.message, err.Error())
+		}
+	}
+}
+
+func TestTagExpressionMatch(t *testing.T) {
+	testData := []struct {
+		input    string
+		tags     []string
+		expected bool
+	}{
+		{"", nil, true},
+		{"", []string{"a"}, true},
+		{"@a", []string{"a"}, true},
+		{"@a", []string{"@a"}, true},
+		{"@a", []string{"b"}, false},
+		{"@a", nil, false},
+		{"not @a", nil, true},
+		{"not @a", []string{"a", "b"}, false},
+		{"@a and @b", []string{"a"}, false},
+		{"@a and @b", []string{"b", "a"}, true},
+		{"@a or @b", []string{"b"}, true},
+		{"@a or @b", []string{"c"}, false},
+		{"@smoke and not (@wip or @flaky)", []string{"smoke"}, true},
+		{"@smoke and not (@wip or @flaky)", []string{"smoke", "flaky"}, false},
+		{"@smoke and not (@wip or @flaky)", []string{"wip"}, false},
+		{"@a or @b and @c", []string{"a"}, true},
+		{"(@a or @b) and @c", []string{"a"}, false},
+		{"@with\\ space", []string{"with space"}, true},
+	}
+
+	for _, tt := range testData {
+		expression, err := ParseTagExpression(tt.input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+		}
+		if match := expression.Evaluate(tt.tags); match != tt.expected {
+			t.Fatalf("Match of %q for %v mismatch, expected %v, got %v", tt.input, tt.tags, tt.expected, match)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package formatter
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	a := []byte("one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n")
+	b := []byte("one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n")
+
+	expected := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if diff := string(Diff("a", a, "b", b)); diff != expected {
+		t.Fatalf("Diff mismatch, expected\n%s\ngot\n%s", expected, diff)
+	}
+	if diff := Diff("a", a, "b", a); diff != nil {
+		t.Fatalf("Expected no diff for the same input but got\n%s", diff)
+	}
+}
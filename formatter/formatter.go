@@ -0,0 +1,313 @@
+// Package formatter renders parsed features back into Gherkin source
+//
+// The output uses two spaces for every level of indentation, aligns the
+// columns of the tables, writes the DocStrings with """ fences, unless the
+// content has a line starting with """, and keeps the comments and tags of
+// the source.
+package formatter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/token"
+)
+
+const indentation = "  "
+
+// Source formats the Gherkin source, the source is returned unchanged along
+// with the errors when it can not be parsed
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.New(string(src)))
+	featureSet := p.Parse()
+	if len(p.Errors()) != 0 {
+		return src, &parser.FileError{ParsingErrors: p.Errors()}
+	}
+	if featureSet == nil || len(featureSet.Features) == 0 {
+		return src, nil
+	}
+	return Format(&featureSet.Features[0]), nil
+}
+
+// Format renders the feature as Gherkin source
+func Format(feature *object.Feature) []byte {
+	p := &printer{comments: feature.Comments}
+
+	p.tags(0, feature.TagTokens, feature.Tags, feature.Token.LineNumber)
+	p.keyword(0, feature.Token.LineNumber, orDefault(feature.Token.Literal, "Feature"), feature.Title)
+	p.description(1, feature.Description)
+
+	if feature.Background != nil {
+		p.background(1, feature.Background)
+	}
+	for _, scenario := range feature.Scenarios {
+		p.scenarioType(1, scenario)
+	}
+	for i := range feature.Rules {
+		p.rule(1, &feature.Rules[i])
+	}
+	p.flushComments(0, 0)
+	return p.out.Bytes()
+}
+
+type printer struct {
+	out      bytes.Buffer
+	comments []token.Token
+}
+
+// line writes a line of text at given indentation level
+func (p *printer) line(level int, text string) {
+	if text != "" {
+		p.out.WriteString(strings.Repeat(indentation, level))
+		p.out.WriteString(text)
+	}
+	p.out.WriteString("\n")
+}
+
+// blankLine separates the blocks, there are no blank lines at the start of
+// the output
+func (p *printer) blankLine() {
+	if p.out.Len() > 0 {
+		p.out.WriteString("\n")
+	}
+}
+
+// flushComments writes the comments that appear before given line in the
+// source, all the remaining comments are written when the line is 0
+func (p *printer) flushComments(level, lineNumber int) {
+	for len(p.comments) > 0 && (lineNumber == 0 || p.comments[0].LineNumber < lineNumber) {
+		comment := p.comments[0].Literal
+		p.comments = p.comments[1:]
+		if comment == "" {
+			p.line(level, "#")
+		} else {
+			p.line(level, "# "+comment)
+		}
+	}
+}
+
+func (p *printer) tags(level int, tokens []token.Token, names []string, lineNumber int) {
+	if len(tokens) > 0 {
+		lineNumber = tokens[0].LineNumber
+	}
+	p.flushComments(level, lineNumber)
+	if len(names) == 0 {
+		return
+	}
+	var tags []string
+	for _, name := range names {
+		tags = append(tags, "@"+name)
+	}
+	p.line(level, strings.Join(tags, " "))
+}
+
+func (p *printer) keyword(level, lineNumber int, keyword, title string) {
+	p.flushComments(level, lineNumber)
+	p.line(level, strings.TrimSpace(keyword+": "+title))
+}
+
+// description writes the description with its common indentation replaced
+// by the indentation of given level
+func (p *printer) description(level int, description string) {
+	for _, line := range DescriptionLines(description) {
+		p.line(level, line)
+	}
+}
+
+// DescriptionLines returns the lines of the description with the indentation
+// shared by all of them removed
+func DescriptionLines(description string) []string {
+	if description == "" {
+		return nil
+	}
+	lines := strings.Split(description, "\n")
+	var prefix *string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if prefix == nil {
+			prefix = &indent
+			continue
+		}
+		for !strings.HasPrefix(indent, *prefix) {
+			*prefix = (*prefix)[:len(*prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		if prefix != nil {
+			line = strings.TrimPrefix(line, *prefix)
+		}
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return lines
+}
+
+func (p *printer) background(level int, background *object.Background) {
+	p.blankLine()
+	p.keyword(level, background.LineNumber, orDefault(background.Keyword, "Background"), background.Title)
+	p.steps(level+1, background.Steps)
+}
+
+func (p *printer) rule(level int, rule *object.Rule) {
+	p.blankLine()
+	p.tags(level, rule.TagTokens, rule.Tags, rule.Token.LineNumber)
+	p.keyword(level, rule.Token.LineNumber, orDefault(rule.Token.Literal, "Rule"), rule.Title)
+	p.description(level+1, rule.Description)
+	if rule.Background != nil {
+		p.background(level+1, rule.Background)
+	}
+	for _, scenario := range rule.Scenarios {
+		p.scenarioType(level+1, scenario)
+	}
+}
+
+func (p *printer) scenarioType(level int, scenarioType object.ScenarioType) {
+	p.blankLine()
+	switch scenario := scenarioType.(type) {
+	case *object.Scenario:
+		p.tags(level, scenario.TagTokens, scenario.Tags, scenario.LineNumber)
+		p.keyword(level, scenario.LineNumber, orDefault(scenario.Keyword, "Scenario"), scenario.ScenarioText)
+		p.scenarioBody(level+1, scenario.Description, scenario.Steps)
+	case *object.ScenarioOutline:
+		p.tags(level, scenario.TagTokens, scenario.Tags, scenario.LineNumber)
+		p.keyword(level, scenario.LineNumber, orDefault(scenario.Keyword, "Scenario Outline"), scenario.ScenarioText)
+		p.scenarioBody(level+1, scenario.Description, scenario.Steps)
+		for i, table := range scenario.Tables {
+			p.examples(level+1, scenario, i, table)
+		}
+	}
+}
+
+func (p *printer) scenarioBody(level int, description string, steps []object.Step) {
+	p.description(level, description)
+	if description != "" {
+		p.blankLine()
+	}
+	p.steps(level, steps)
+}
+
+func (p *printer) examples(level int, outline *object.ScenarioOutline, i int, table object.Table) {
+	keyword := token.Token{Literal: "Examples"}
+	if i < len(outline.TableTokens) {
+		keyword = outline.TableTokens[i]
+	}
+	var tagTokens []token.Token
+	var tags []string
+	if i < len(outline.TableTagTokens) {
+		tagTokens = outline.TableTagTokens[i]
+	}
+	if i < len(outline.TableTags) {
+		tags = outline.TableTags[i]
+	}
+
+	p.blankLine()
+	p.tags(level, tagTokens, tags, keyword.LineNumber)
+	name := ""
+	if i < len(outline.TableNames) {
+		name = outline.TableNames[i]
+	}
+	p.keyword(level, keyword.LineNumber, keyword.Literal, name)
+	if i < len(outline.TableDescriptions) && outline.TableDescriptions[i] != "" {
+		p.description(level+1, outline.TableDescriptions[i])
+		p.blankLine()
+	}
+	p.table(level+1, table)
+}
+
+func (p *printer) steps(level int, steps []object.Step) {
+	for _, step := range steps {
+		p.flushComments(level, step.LineNumber)
+		p.line(level, strings.TrimSpace(step.Token.Literal+" "+step.Text))
+		p.table(level+1, step.Table)
+		if step.DocString != nil {
+			p.docString(level+1, step.DocString)
+		}
+	}
+}
+
+// table writes the rows of the table with the cells of every column padded
+// to the width of the widest cell in the column
+func (p *printer) table(level int, table object.Table) {
+	var widths []int
+	for _, row := range table {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := DisplayWidth(cell.Literal); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	for _, row := range table {
+		if len(row) == 0 {
+			continue
+		}
+		p.flushComments(level, row[0].LineNumber)
+		var line strings.Builder
+		line.WriteString("|")
+		for i, cell := range row {
+			line.WriteString(" ")
+			line.WriteString(cell.Literal)
+			line.WriteString(strings.Repeat(" ", widths[i]-DisplayWidth(cell.Literal)))
+			line.WriteString(" |")
+		}
+		p.line(level, line.String())
+	}
+}
+
+// docString writes the DocString between """ fences with the media type on
+// the opening one, the content is re-indented relative to the fences
+func (p *printer) docString(level int, docString *object.DocString) {
+	lines := DocStringLines(docString)
+	fence := `"""`
+	for _, line := range lines {
+		// A line starting with """ would close the DocString early
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			fence = "```"
+			break
+		}
+	}
+	p.line(level, fence+docString.MediaType)
+	for _, line := range lines {
+		p.line(level, line)
+	}
+	p.line(level, fence)
+}
+
+// DocStringLines returns the lines of the DocString content with the
+// indentation of the fences removed
+func DocStringLines(docString *object.DocString) []string {
+	if docString.Content == "" {
+		return nil
+	}
+	lines := strings.Split(docString.Content, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			line = trimIndent(line, docString.Column-1)
+		}
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return lines
+}
+
+// trimIndent removes up to n whitespace characters from the start of the line
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && len(line) > 0 && (line[0] == ' ' || line[0] == '\t'); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,133 @@
+package formatter
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/pickles"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestFormatGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.feature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("Expected feature files in testdata")
+	}
+
+	for _, input := range inputs {
+		src, err := ioutil.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := Source(src)
+		if err != nil {
+			t.Fatalf("%v: %v", input, err)
+		}
+
+		golden := strings.TrimSuffix(input, ".feature") + ".golden"
+		if *update {
+			if err := ioutil.WriteFile(golden, formatted, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(formatted, expected) {
+			t.Fatalf("%v: formatted source mismatch\n%s", input, Diff(golden, expected, "formatted", formatted))
+		}
+
+		// Formatting the formatted source does not change it
+		again, err := Source(formatted)
+		if err != nil {
+			t.Fatalf("%v: %v", golden, err)
+		}
+		if !bytes.Equal(again, formatted) {
+			t.Fatalf("%v: formatting is not idempotent\n%s", input, Diff("formatted", formatted, "again", again))
+		}
+
+		// The formatted source compiles to the same scenarios
+		if compiled, expected := compile(t, formatted), compile(t, src); compiled != expected {
+			t.Fatalf("%v: scenarios mismatch\n%s", input, Diff("source", []byte(expected), "formatted", []byte(compiled)))
+		}
+	}
+}
+
+// compile returns a summary of the pickles in the source
+func compile(t *testing.T, src []byte) string {
+	p := parser.New(lexer.New(string(src)))
+	featureSet := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatal(p.Errors()[0].GetMessage())
+	}
+
+	var out strings.Builder
+	compiled, err := pickles.Compile(&featureSet.Features[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pickle := range compiled {
+		fmt.Fprintf(&out, "%v", pickle.Name)
+		for _, tag := range pickle.Tags {
+			fmt.Fprintf(&out, " @%v", tag.Name)
+		}
+		fmt.Fprintln(&out)
+		for _, step := range pickle.Steps {
+			fmt.Fprintf(&out, "  %v %v\n", step.Keyword, step.Text)
+			for _, row := range step.Table {
+				for _, cell := range row {
+					fmt.Fprintf(&out, "    | %v", cell.Literal)
+				}
+				fmt.Fprintln(&out)
+			}
+			if step.DocString != nil {
+				fmt.Fprintf(&out, "    %q\n", DocStringLines(step.DocString))
+			}
+		}
+	}
+	return out.String()
+}
+
+func TestSourceErrors(t *testing.T) {
+	src := []byte("Feature: broken\n\tScenario: no steps\n\t\t| a |\n")
+	formatted, err := Source(src)
+	if err == nil {
+		t.Fatal("Expected an error for invalid source")
+	}
+	if !bytes.Equal(formatted, src) {
+		t.Fatalf("Expected the source to be returned unchanged but got %q", formatted)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	testData := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"日本語", 6},
+		{"🎉", 2},
+		{"é", 1},
+		{"कखग", 3},
+		{"Ａｂ", 4},
+	}
+
+	for _, tt := range testData {
+		if width := DisplayWidth(tt.input); width != tt.expected {
+			t.Fatalf("Width of %q mismatch, expected %v, got %v", tt.input, tt.expected, width)
+		}
+	}
+}
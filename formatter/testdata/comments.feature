@@ -0,0 +1,43 @@
+# language: en
+# leading comment
+@featureTag   @other
+Feature:    Formatting   stuff
+      This is a description
+        with an indented line
+
+      and a blank line before this one
+
+  Background: setup
+    Given a step with "string" and 12
+      |a|b|
+      |long value|日本語|
+
+# comment before scenario
+    @tag
+  Scenario: first
+      Some scenario description
+    When I do "it"
+        """
+        doc line
+          indented doc line
+        """
+    # comment between steps
+    Then done
+
+  Scenario Outline: outline <x>
+    Given <x> value
+    @ex
+      Examples:
+      Examples description
+        | x |
+        | 1 |
+        | 🎉 |
+
+  @ruleTag
+  Rule: the rule
+    Background:
+      Given rule background
+
+    Scenario: in rule
+      * star step
+# trailing comment
@@ -0,0 +1,18 @@
+Feature: DocStrings
+  Scenario: fences
+    Given a json DocString
+        """json
+        {"name": "gorkin"}
+        """
+    And a DocString with backtick fences
+      ```
+      some text
+        indented
+      ```
+    And a markdown DocString with backtick fences
+      ```markdown
+      # Title
+      """
+      quoted
+      """
+      ```
@@ -0,0 +1,20 @@
+Feature: Outlines
+Scenario Outline: eating <count> <food>
+Given there are <start> <food>
+When I eat <count> <food>
+|food|count|
+|<food>|<count>|
+Then I should have <left> <food>
+"""
+<left> <food> left
+"""
+@small
+Examples:   small amounts
+|start|count|left|food|
+|12|5|7|cucumbers|
+|20|5|15|apples|
+@large @slow
+Examples: 
+Larger amounts
+|start|count|left|food|
+|1200|500|700|cucumbers|
@@ -0,0 +1,25 @@
+@feature
+Feature: Rules
+    Rules group scenarios
+
+  Background:
+          Given a feature background
+
+  @first
+  Rule: first rule
+      The first rule
+
+      Background: rule setup
+        Given a rule background
+
+      Scenario: first scenario
+          Given a step
+          And another step
+
+      @tagged
+      Scenario: second scenario
+          Given a step
+
+  Rule: second rule
+      Example: example keyword
+          * a star step
@@ -0,0 +1,9 @@
+# language: de
+Funktionalität: Breite Zeichen
+	Szenario: Tabellen mit breiten Zeichen
+		Angenommen die folgenden Namen
+			| name | 名前 |
+			| Zoë | 山田太郎 |
+			| 🎉🎉 | x |
+			| é | कखग |
+		Dann sind die Spalten ausgerichtet
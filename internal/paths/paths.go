@@ -0,0 +1,30 @@
+// Package paths has the helpers for the slash separated paths of the feature
+// files shared by the commands writing a file for every feature
+package paths
+
+import (
+	"path"
+	"strings"
+)
+
+// CommonDir returns the longest directory shared by all the slash separated
+// paths, it is empty when they share none
+func CommonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	common := strings.Split(path.Dir(paths[0]), "/")
+	for _, p := range paths[1:] {
+		parts := strings.Split(path.Dir(p), "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	dir := strings.Join(common, "/")
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,24 @@
+package paths
+
+import "testing"
+
+func TestCommonDir(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"a.feature"}, ""},
+		{[]string{"specs/a.feature"}, "specs"},
+		{[]string{"/abs/specs/a.feature", "/abs/specs/sub/b.feature"}, "/abs/specs"},
+		{[]string{"specs/a.feature", "other/a.feature"}, ""},
+		{[]string{"specs/shop/a.feature", "specs/shopping/b.feature"}, "specs"},
+		{[]string{"../other/a.feature", "b.feature"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := CommonDir(tt.paths); got != tt.expected {
+			t.Fatalf("Directory mismatch for %v, expected %q, got %q", tt.paths, tt.expected, got)
+		}
+	}
+}
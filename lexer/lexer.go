@@ -1,68 +1,295 @@
 package lexer
 
 import (
+	"bytes"
+	"io"
 	"io/ioutil"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/dpakach/gorkin/token"
 )
 
+var languageRegexp = regexp.MustCompile(`^#\s*language\s*:\s*([a-zA-Z0-9_-]+)\s*$`)
+
 // Lexer is the Lexer object for reading through the Gherkin input
 type Lexer struct {
 	input         string
 	position      int
 	readPosition  int
-	ch            byte
+	ch            rune
+	column        int
 	currentLineNo int
 	FilePath      string
+
+	// Language is the language code from the `# language:` header of the input
+	Language string
+	// Dialect is the keyword dialect used for the input, it falls back to the
+	// default dialect when the Language is unknown
+	Dialect *token.Dialect
+
+	keywords  []token.Keyword
+	lineStart bool
+	pending   []token.Token
+
+	// lineOffset is the offset of the start of the line the current token
+	// is on, last and keep are the starts of the lines of the last two tokens
+	// returned
+	lineOffset int
+	last       int
+	keep       int
+
+	// A Lexer created by NewFromReader reads the input into buf instead,
+	// which only holds the input from the offset base. The lines before keep
+	// are dropped when more is read so the buffer is reused.
+	reader  io.Reader
+	buf     []byte
+	base    int
+	lineEnd int
+	eof     bool
+	err     error
 }
 
+// bufferSize is the size of the reads of a Lexer created by NewFromReader
+const bufferSize = 4096
+
 // New Creates a new Lexer object for given input
 func New(input string) *Lexer {
 	l := &Lexer{input: input, currentLineNo: 1}
-	l.readChar()
+	l.init()
 	return l
 }
 
 // NewFromFile Creates a new Lexer object for given feature file
-func NewFromFile(path string) *Lexer {
+func NewFromFile(path string) (*Lexer, error) {
 	dat, err := ioutil.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	l := &Lexer{input: string(dat), FilePath: path, currentLineNo: 1}
+	l.init()
+	return l, nil
+}
+
+// NewFromReader Creates a new Lexer object reading the input from given reader
+//
+// The input is read as the tokens are lexed and only the lines of the last
+// two tokens are kept in memory, so Slice can only return the input of those
+// lines. A token like a PYSTRING spanning many lines is buffered in full.
+// The name is used as the FilePath of the lexer.
+func NewFromReader(r io.Reader, name string) (*Lexer, error) {
+	l := &Lexer{
+		FilePath:      name,
+		currentLineNo: 1,
+		reader:        r,
+		buf:           make([]byte, 0, 2*bufferSize),
+		lineEnd:       -1,
+	}
+	// The language header has to be read before any token
+	for !l.eof && !headerRead(string(l.buf)) {
+		l.read()
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.fill()
+	l.init()
+	return l, nil
+}
+
+// Err returns the error that stopped reading the input of a Lexer created by
+// NewFromReader, the lexer returns EOF after such an error
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+func (l *Lexer) init() {
+	header := l.input
+	if l.reader != nil {
+		header = string(l.buf)
+	}
+	l.Language = detectLanguage(header)
+	dialect, ok := token.GetDialect(l.Language)
+	if !ok {
+		dialect, _ = token.GetDialect(token.DefaultLanguage)
+	}
+	l.Dialect = dialect
+	l.keywords = dialect.Keywords()
+	l.lineStart = true
 	l.readChar()
-	return l
+}
+
+// detectLanguage looks for the `# language:` header in the comments and
+// blank lines at the top of the input
+func detectLanguage(input string) string {
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if match := languageRegexp.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+	}
+	return token.DefaultLanguage
+}
+
+// headerRead checks if the input holds the comment and blank lines at the
+// top along with the first line after them
+func headerRead(input string) bool {
+	for {
+		i := strings.IndexByte(input, '\n')
+		if i < 0 {
+			return false
+		}
+		line := strings.TrimSpace(input[:i])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+		input = input[i+1:]
+	}
+}
+
+// Slice returns the raw input between the given byte offsets
+func (l *Lexer) Slice(start, end int) string {
+	if end > l.end() {
+		end = l.end()
+	}
+	if start < l.base || start > end {
+		return ""
+	}
+	return l.slice(start, end)
+}
+
+// slice returns the buffered input between the given byte offsets
+func (l *Lexer) slice(start, end int) string {
+	if l.reader != nil {
+		return string(l.buf[start-l.base : end-l.base])
+	}
+	return l.input[start:end]
+}
+
+// end returns the offset of the end of the buffered input
+func (l *Lexer) end() int {
+	if l.reader != nil {
+		return l.base + len(l.buf)
+	}
+	return len(l.input)
+}
+
+// runeAt decodes the buffered rune at the given offset, it returns a zero
+// width past the end of the buffered input
+func (l *Lexer) runeAt(offset int) (rune, int) {
+	if offset >= l.end() {
+		return 0, 0
+	}
+	if l.reader != nil {
+		return utf8.DecodeRune(l.buf[offset-l.base:])
+	}
+	return utf8.DecodeRuneInString(l.input[offset:])
+}
+
+// hasPrefix checks if the buffered input at the given offset starts with
+// prefix
+func (l *Lexer) hasPrefix(offset int, prefix string) bool {
+	if l.reader != nil {
+		rest := l.buf[offset-l.base:]
+		return len(rest) >= len(prefix) && string(rest[:len(prefix)]) == prefix
+	}
+	return strings.HasPrefix(l.input[offset:], prefix)
+}
+
+// read reads the next chunk from the reader into the buffer, dropping the
+// lines before keep. The buffer only grows when those lines and the chunk
+// don't fit in it.
+func (l *Lexer) read() {
+	if drop := l.keep - l.base; drop > 0 {
+		l.buf = l.buf[:copy(l.buf, l.buf[drop:])]
+		l.base = l.keep
+	}
+	if len(l.buf)+bufferSize > cap(l.buf) {
+		buf := make([]byte, len(l.buf), 2*cap(l.buf)+bufferSize)
+		copy(buf, l.buf)
+		l.buf = buf
+	}
+	n, err := l.reader.Read(l.buf[len(l.buf) : len(l.buf)+bufferSize])
+	l.buf = l.buf[:len(l.buf)+n]
+	if err == io.EOF {
+		l.eof = true
+	} else if err != nil {
+		l.eof = true
+		l.err = err
+	}
+}
+
+// fill reads from the reader until the whole line at the read position is
+// in the buffer, so the lexer can look ahead till the end of the line
+func (l *Lexer) fill() {
+	if l.reader == nil || l.readPosition <= l.lineEnd {
+		return
+	}
+	searched := l.readPosition
+	for {
+		if from := searched - l.base; from < len(l.buf) {
+			if i := bytes.IndexByte(l.buf[from:], '\n'); i >= 0 {
+				l.lineEnd = searched + i
+				return
+			}
+			searched = l.end()
+		}
+		if l.eof {
+			return
+		}
+		l.read()
+	}
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
+	if l.ch == '\n' {
+		l.column = 0
+		l.lineOffset = l.readPosition
+	}
+	width := 1
+	if end := l.end(); l.readPosition >= end {
 		l.ch = 0
+		l.position = end
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = l.runeAt(l.readPosition)
+		l.position = l.readPosition
 	}
-	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition = l.position + width
+	l.column++
+	l.fill()
 }
 
-func newToken(tokenType token.Type, ch byte) token.Token {
+func newToken(tokenType token.Type, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-func isLetter(ch byte) bool {
+// isLetter checks if given rune is a word character, this includes the
+// letters and marks of every script along with symbols like emoji
+func isLetter(ch rune) bool {
+	if ch > unicode.MaxASCII {
+		return unicode.IsLetter(ch) || unicode.IsMark(ch) || unicode.IsSymbol(ch) || unicode.Is(unicode.Join_Control, ch)
+	}
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || ch == '-' || ch == '.' || ch == '+'
 }
 
-func isValidBodyChar(ch byte) bool {
+func isValidBodyChar(ch rune) bool {
 	return isLetter(ch) || ch == ' ' || ch == '_' || ch == '-'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func isWhitespace(ch byte) bool {
+func isWhitespace(ch rune) bool {
 	if ch == ' ' || ch == '\t' || ch == '\r' {
 		return true
 	}
@@ -80,14 +307,12 @@ func (l *Lexer) readNumber() string {
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition > len(l.input) {
-		return 0
-	}
-	return l.input[l.readPosition]
+func (l *Lexer) peekChar() rune {
+	ch, _ := l.runeAt(l.readPosition)
+	return ch
 }
 
 func (l *Lexer) readString() string {
@@ -99,25 +324,34 @@ func (l *Lexer) readString() string {
 		}
 	}
 
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
-func (l *Lexer) readPyString() string {
-	position := l.position + 1
-	for {
+// readDocString reads a DocString opened by the fence at the current
+// position, the rest of the opening line is its media type and it is closed
+// by a line starting with the same fence
+func (l *Lexer) readDocString(fence string) string {
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
-		if l.ch == '\n' {
-			l.currentLineNo++
-		}
-		if (l.ch == '"' && l.peekChar() == '"') || l.ch == 0 {
-			if l.peekChar() == '"' {
-				break
+	}
+	position := l.position
+	for l.ch != 0 {
+		l.currentLineNo++
+		l.readChar()
+		lineStart := l.position
+		l.skipWhitespace()
+		if l.hasPrefix(l.position, fence) {
+			content := l.slice(position, lineStart)
+			for range fence {
+				l.readChar()
 			}
+			return content
+		}
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
 		}
 	}
-
-	res := l.input[position:l.position]
-	return res
+	return l.slice(position, l.position)
 }
 
 func (l *Lexer) readExampleValue() string {
@@ -129,7 +363,7 @@ func (l *Lexer) readExampleValue() string {
 		}
 	}
 
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
 func (l *Lexer) readWord() string {
@@ -141,7 +375,7 @@ func (l *Lexer) readWord() string {
 		}
 		break
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
 func (l *Lexer) readTillLineBreak() string {
@@ -152,7 +386,7 @@ func (l *Lexer) readTillLineBreak() string {
 		}
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
 func (l *Lexer) readBody() string {
@@ -161,25 +395,68 @@ func (l *Lexer) readBody() string {
 	for isValidBodyChar(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
+// readTableData reads a table cell until the next pipe or the end of the line
 func (l *Lexer) readTableData() string {
 	position := l.position
-	for {
+	for l.ch != '|' && l.ch != '\n' && l.ch != 0 {
 		l.readChar()
-		if l.peekChar() == '|' || l.ch == 0 {
-			break
+	}
+	return l.slice(position, l.position)
+}
+
+// readKeyword reads the longest keyword of the dialect at the current position,
+// keywords are only recognised at the start of a line
+func (l *Lexer) readKeyword() (token.Keyword, bool) {
+	if !l.lineStart {
+		return token.Keyword{}, false
+	}
+	for _, keyword := range l.keywords {
+		if !l.hasPrefix(l.position, keyword.Literal) {
+			continue
+		}
+		end := l.position + len(keyword.Literal)
+		last, _ := utf8.DecodeLastRuneInString(keyword.Literal)
+		next, _ := l.runeAt(end)
+		if isWordRune(last) && isWordRune(next) {
+			continue
+		}
+		// Block keywords like Feature or Scenario are only keywords when
+		// they are followed by a colon
+		after := end
+		for ch, _ := l.runeAt(after); ch == ' ' || ch == '\t'; ch, _ = l.runeAt(after) {
+			after++
+		}
+		if !token.IsStepToken(keyword.Type) && !l.hasPrefix(after, ":") {
+			continue
+		}
+		for l.position < end {
+			l.readChar()
 		}
+		return keyword, true
 	}
+	return token.Keyword{}, false
+}
 
-	return l.input[position:l.position]
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
 }
 
 // NextToken returns the next token in the lexer
 func (l *Lexer) NextToken() token.Token {
+	if len(l.pending) > 0 {
+		tok := l.pending[0]
+		l.pending = l.pending[1:]
+		return tok
+	}
+
 	var tok token.Token
 	l.skipWhitespace()
+	column, offset := l.column, l.position
+	line := l.lineOffset
+	l.keep, l.last = l.last, line
 	switch l.ch {
 	case 0:
 		tok.Literal = token.EOF.String()
@@ -207,12 +484,8 @@ func (l *Lexer) NextToken() token.Token {
 				l.readChar()
 			} else {
 				tok.LineNumber = l.currentLineNo
-				l.readChar()
 				tok.Type = token.PYSTRING
-				tok.Literal = strings.TrimSpace(l.readPyString())
-				l.readChar()
-				l.readChar()
-				l.readChar()
+				tok.Literal = strings.TrimSpace(l.readDocString(`"""`))
 			}
 		}
 	case '@':
@@ -243,6 +516,8 @@ func (l *Lexer) NextToken() token.Token {
 	case '|':
 		l.readChar()
 		l.skipWhitespace()
+		tok.Column = l.column
+		tok.Offset = l.position
 		if l.ch == '\n' {
 			tok.Type = token.NEWLINE
 			tok.Literal = token.NEWLINE.String()
@@ -257,15 +532,15 @@ func (l *Lexer) NextToken() token.Token {
 			position := l.position
 
 			for {
-				if l.ch != '\n' && l.ch != '|' {
+				if l.ch != '\n' && l.ch != '|' && l.ch != 0 {
 					l.readChar()
-				} else if l.ch == '\n' {
+				} else if l.ch != '|' {
 					tok.Type = token.COMMENT
-					tok.Literal = strings.TrimSpace(l.input[position:l.position])
+					tok.Literal = strings.TrimSpace(l.slice(position, l.position))
 					break
 				} else {
 					tok.Type = token.TABLEDATA
-					tok.Literal = strings.TrimSpace(l.input[position-1 : l.position])
+					tok.Literal = strings.TrimSpace(l.slice(position-1, l.position))
 					break
 				}
 			}
@@ -275,29 +550,54 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok.Type = token.TABLEDATA
 			tok.Literal = strings.TrimSpace(l.readTableData())
-			l.readChar()
 		}
 	default:
-		if isDigit(l.ch) {
+		if l.lineStart && l.hasPrefix(l.position, "```") {
+			tok.LineNumber = l.currentLineNo
+			tok.Type = token.PYSTRING
+			tok.Literal = strings.TrimSpace(l.readDocString("```"))
+		} else if keyword, ok := l.readKeyword(); ok {
+			tok.Literal = keyword.Literal
+			tok.Type = keyword.Type
+			if keyword.Type == token.OUTLINE {
+				// Scenario outlines are emitted as a SCENARIO token followed
+				// by an OUTLINE token regardless of the dialect
+				scenario, outline := l.Dialect.SplitOutline(keyword.Literal)
+				tok.Literal = scenario
+				tok.Type = token.SCENARIO
+				start := len(keyword.Literal) - len(outline)
+				l.pending = append(l.pending, token.Token{
+					Type:       token.OUTLINE,
+					Literal:    outline,
+					LineNumber: l.currentLineNo,
+					Column:     column + utf8.RuneCountInString(keyword.Literal[:start]),
+					Offset:     offset + start,
+				})
+			}
+		} else if isDigit(l.ch) {
 			tok.Literal = l.readNumber()
 			tok.Type = token.NUMBER
 		} else {
 			word := l.readWord()
-			if key, ok := token.GherkinKeyword[word]; ok {
-				tok.Literal = word
-				tok.Type = key
-			} else {
-				body := l.readBody()
-				tok.Literal = strings.TrimSpace(word + body)
-				tok.Type = token.STEPBODY
-			}
+			body := l.readBody()
+			tok.Literal = strings.TrimSpace(word + body)
+			tok.Type = token.STEPBODY
 		}
 	}
 	if tok.LineNumber == 0 {
 		tok.LineNumber = l.currentLineNo
 	}
+	if tok.Column == 0 {
+		tok.Column = column
+		tok.Offset = offset
+	}
 	if tok.Type == token.NEWLINE {
 		l.currentLineNo++
+	} else {
+		// The tokens after one spanning many lines, like a PYSTRING, count
+		// as being on the line the long token started at
+		l.lineOffset = line
 	}
+	l.lineStart = tok.Type == token.NEWLINE
 	return tok
 }
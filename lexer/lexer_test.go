@@ -3,6 +3,9 @@ package lexer
 import "testing"
 import "github.com/dpakach/gorkin/token"
 import "fmt"
+import "io"
+import "strings"
+import "testing/iotest"
 
 func TestNextToken(t *testing.T) {
 	input := `
@@ -206,3 +209,395 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenDialects(t *testing.T) {
+	tests := []struct {
+		input    string
+		language string
+		expected []token.Token
+	}{
+		{
+			`# language: de
+			Funktionalität: Beispiele
+			  Szenariogrundriss: Hallo
+			    Angenommen ich bin da
+			    Gegeben sei noch was`,
+			"de",
+			[]token.Token{
+				{Type: token.COMMENT, Literal: "language: de", LineNumber: 1},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 1},
+				{Type: token.FEATURE, Literal: "Funktionalität", LineNumber: 2},
+				{Type: token.COLON, Literal: ":", LineNumber: 2},
+				{Type: token.STEPBODY, Literal: "Beispiele", LineNumber: 2},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 2},
+				{Type: token.SCENARIO, Literal: "Szenariogrundriss", LineNumber: 3},
+				{Type: token.OUTLINE, Literal: "", LineNumber: 3},
+				{Type: token.COLON, Literal: ":", LineNumber: 3},
+				{Type: token.STEPBODY, Literal: "Hallo", LineNumber: 3},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 3},
+				{Type: token.GIVEN, Literal: "Angenommen", LineNumber: 4},
+				{Type: token.STEPBODY, Literal: "ich bin da", LineNumber: 4},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 4},
+				{Type: token.GIVEN, Literal: "Gegeben sei", LineNumber: 5},
+				{Type: token.STEPBODY, Literal: "noch was", LineNumber: 5},
+				{Type: token.EOF, Literal: token.EOF.String(), LineNumber: 5},
+			},
+		},
+		{
+			`
+			#language:ne
+			परिदृश्य रूपरेखा:
+			दिइएको`,
+			"ne",
+			[]token.Token{
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 1},
+				{Type: token.COMMENT, Literal: "language:ne", LineNumber: 2},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 2},
+				{Type: token.SCENARIO, Literal: "परिदृश्य", LineNumber: 3},
+				{Type: token.OUTLINE, Literal: "रूपरेखा", LineNumber: 3},
+				{Type: token.COLON, Literal: ":", LineNumber: 3},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 3},
+				{Type: token.GIVEN, Literal: "दिइएको", LineNumber: 4},
+				{Type: token.EOF, Literal: token.EOF.String(), LineNumber: 4},
+			},
+		},
+		{
+			`Scenario Template: Example
+			* step Given`,
+			"en",
+			[]token.Token{
+				{Type: token.SCENARIO, Literal: "Scenario", LineNumber: 1},
+				{Type: token.OUTLINE, Literal: "Template", LineNumber: 1},
+				{Type: token.COLON, Literal: ":", LineNumber: 1},
+				{Type: token.STEPBODY, Literal: "Example", LineNumber: 1},
+				{Type: token.NEWLINE, Literal: token.NEWLINE.String(), LineNumber: 1},
+				{Type: token.AND, Literal: "*", LineNumber: 2},
+				{Type: token.STEPBODY, Literal: "step Given", LineNumber: 2},
+				{Type: token.EOF, Literal: token.EOF.String(), LineNumber: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		if l.Language != tt.language {
+			t.Fatalf("Language wrong. expected=%q, got=%q", tt.language, l.Language)
+		}
+		for i, expected := range tt.expected {
+			tok := l.NextToken()
+			if tok.Type != expected.Type {
+				t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, expected.Type, tok.Type)
+			}
+			if tok.Literal != expected.Literal {
+				t.Fatalf("tests[%d] - token literal wrong. expected=%q, got=%q", i, expected.Literal, tok.Literal)
+			}
+			if tok.LineNumber != expected.LineNumber {
+				t.Fatalf("tests[%d] - Line Number wrong. expected=%v, got=%v", i, expected.LineNumber, tok.LineNumber)
+			}
+		}
+	}
+}
+
+func TestNextTokenUnicode(t *testing.T) {
+	input := `Feature: Ünïcödé
+  Scenario: 日本語 🎉
+    Given I order 2 crème brûlée 🍮 for "Zoë"
+      | naïve | 👍🏽 |`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+		expectedLineNo  int
+		expectedColumn  int
+		expectedOffset  int
+	}{
+		{token.FEATURE, "Feature", 1, 1, 0},
+		{token.COLON, ":", 1, 8, 7},
+		{token.STEPBODY, "Ünïcödé", 1, 10, 9},
+		{token.NEWLINE, token.NEWLINE.String(), 1, 17, 20},
+		{token.SCENARIO, "Scenario", 2, 3, 23},
+		{token.COLON, ":", 2, 11, 31},
+		{token.STEPBODY, "日本語 🎉", 2, 13, 33},
+		{token.NEWLINE, token.NEWLINE.String(), 2, 18, 47},
+		{token.GIVEN, "Given", 3, 5, 52},
+		{token.STEPBODY, "I order", 3, 11, 58},
+		{token.NUMBER, "2", 3, 19, 66},
+		{token.STEPBODY, "crème brûlée 🍮 for", 3, 21, 68},
+		{token.STRING, "Zoë", 3, 40, 93},
+		{token.NEWLINE, token.NEWLINE.String(), 3, 45, 99},
+		{token.TABLEDATA, "naïve", 4, 9, 108},
+		{token.TABLEDATA, "👍🏽", 4, 17, 117},
+		{token.EOF, token.EOF.String(), 4, 0, 0},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.LineNumber != tt.expectedLineNo {
+			t.Fatalf("tests[%d] - Line Number wrong. expected=%v, got=%v", i, tt.expectedLineNo, tok.LineNumber)
+		}
+		if tok.Type == token.EOF {
+			continue
+		}
+		if tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - Column wrong. expected=%v, got=%v", i, tt.expectedColumn, tok.Column)
+		}
+		if tok.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - Offset wrong. expected=%v, got=%v", i, tt.expectedOffset, tok.Offset)
+		}
+		if input[tok.Offset:tok.Offset+len(tok.Literal)] != tok.Literal && tok.Type != token.NEWLINE && tok.Type != token.STRING {
+			t.Fatalf("tests[%d] - Offset does not point to the literal, got %q", i, input[tok.Offset:])
+		}
+	}
+}
+
+func TestNextTokenUnterminatedPyString(t *testing.T) {
+	input := `Given a step
+	"""
+	never closed`
+
+	l := New(input)
+	expected := []token.Type{token.GIVEN, token.STEPBODY, token.NEWLINE, token.PYSTRING, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+}
+
+func TestNextTokenDocStringFences(t *testing.T) {
+	input := "Given a step\n\t\"\"\"json\n\t{\"a\": 1}\n\t\"\"\"\n\tAnd a step\n\t```\n\t\"\"\"\n\tquoted\n\t\"\"\"\n\t```\n\tThen it works"
+
+	expected := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.GIVEN, "Given", 1},
+		{token.STEPBODY, "a step", 1},
+		{token.NEWLINE, token.NEWLINE.String(), 1},
+		{token.PYSTRING, `{"a": 1}`, 2},
+		{token.NEWLINE, token.NEWLINE.String(), 4},
+		{token.AND, "And", 5},
+		{token.STEPBODY, "a step", 5},
+		{token.NEWLINE, token.NEWLINE.String(), 5},
+		{token.PYSTRING, "\"\"\"\n\tquoted\n\t\"\"\"", 6},
+		{token.NEWLINE, token.NEWLINE.String(), 10},
+		{token.THEN, "Then", 11},
+	}
+
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral || tok.LineNumber != tt.expectedLine {
+			t.Fatalf("tests[%d] - token wrong. expected=%v %q on line %v, got=%v %q on line %v", i, tt.expectedType, tt.expectedLiteral, tt.expectedLine, tok.Type, tok.Literal, tok.LineNumber)
+		}
+	}
+}
+
+func TestNextTokenInputEnd(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Type
+	}{
+		{"@", []token.Type{token.TAG, token.EOF}},
+		{"<", []token.Type{token.EXAMPLEVALUE, token.EOF}},
+		{`"`, []token.Type{token.STRING, token.EOF}},
+		{"| #", []token.Type{token.COMMENT, token.EOF}},
+		{"\xff", []token.Type{token.STEPBODY, token.EOF}},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for i, expected := range tt.expected {
+			tok := l.NextToken()
+			if tok.Type != expected {
+				t.Fatalf("%q tests[%d] - tokentype wrong. expected=%q, got=%q", tt.input, i, expected, tok.Type)
+			}
+		}
+		// Reading past the end keeps returning EOF
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("%q - tokentype wrong. expected=%q, got=%q", tt.input, token.EOF, tok.Type)
+		}
+	}
+}
+
+func TestNewFromFileMissing(t *testing.T) {
+	l, err := NewFromFile("does/not/exist.feature")
+	if err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+	if l != nil {
+		t.Fatalf("Expected no lexer but got %v", l)
+	}
+}
+
+func TestNextTokenTableCells(t *testing.T) {
+	input := `|a|bc|
+| long value |日本語|`
+
+	expected := []token.Token{
+		{Type: token.TABLEDATA, Literal: "a", Column: 2},
+		{Type: token.TABLEDATA, Literal: "bc", Column: 4},
+		{Type: token.NEWLINE, Literal: token.NEWLINE.String(), Column: 7},
+		{Type: token.TABLEDATA, Literal: "long value", Column: 3},
+		{Type: token.TABLEDATA, Literal: "日本語", Column: 15},
+		{Type: token.EOF, Literal: token.EOF.String(), Column: 19},
+	}
+
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.Type || tok.Literal != tt.Literal || tok.Column != tt.Column {
+			t.Fatalf("tests[%d] - token wrong. expected=%+v, got=%+v", i, tt, tok)
+		}
+	}
+}
+
+// readerInputs are lexed both from a string and from a reader
+var readerInputs = []string{
+	"",
+	"Feature: no line break at the end",
+	"# language: fr\n\n# comment\nFonctionnalité: Bonjour\n  Plan du scénario: Salut\n    Soit un pas\n",
+	"Feature: Ünïcödé\n  Scenario: 日本語 🎉\n    Given I order 2 crème brûlée 🍮 for \"Zoë\"\n      | naïve | 👍🏽 |\n",
+	"Feature: tables\n  Scenario Outline: <a>\n    Given <a> and <b>\n    Examples:\n      | a | b | # comment\n      |#x | |\n      | 1 | 2 |",
+	"Feature: unterminated\n  Scenario: s\n    Given a step\n    \"\"\"\n    never closed",
+	"Given a step\r\n\t\"\"\"json\r\n\t{\"a\": \"b\"}\r\n\t\"\"\"\r\n",
+	generateFeature(20),
+}
+
+// generateFeature returns a feature with given number of scenarios, each with
+// a table and a DocString spanning many lines
+func generateFeature(scenarios int) string {
+	var b strings.Builder
+	b.WriteString("# language: en\n@tag\nFeature: generated\n  a description\n\n")
+	for i := 0; i < scenarios; i++ {
+		fmt.Fprintf(&b, "  @scenario-%d\n  Scenario Outline: scenario %d <value>\n", i, i)
+		b.WriteString("    Given a table\n      | name | value |\n      | a    | 1     |\n")
+		b.WriteString("    When a DocString is given\n      \"\"\"\n")
+		for j := 0; j < 50; j++ {
+			fmt.Fprintf(&b, "      line %d of the DocString with \"quotes\" and 日本語\n", j)
+		}
+		b.WriteString("      \"\"\"\n    Then <value> is used # comment\n    Examples:\n      | value |\n      | 42    |\n\n")
+	}
+	return b.String()
+}
+
+func TestNewFromReader(t *testing.T) {
+	readers := map[string]func(r io.Reader) io.Reader{
+		"reader":   func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+		"data err": iotest.DataErrReader,
+	}
+
+	for _, input := range readerInputs {
+		for name, newReader := range readers {
+			expected := New(input)
+			l, err := NewFromReader(newReader(strings.NewReader(input)), "test.feature")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.FilePath != "test.feature" {
+				t.Fatalf("FilePath mismatch, expected %v, got %v", "test.feature", l.FilePath)
+			}
+			if l.Language != expected.Language {
+				t.Fatalf("%v: Language mismatch, expected %v, got %v", name, expected.Language, l.Language)
+			}
+			for i := 0; ; i++ {
+				want, got := expected.NextToken(), l.NextToken()
+				if got != want {
+					t.Fatalf("%v %q tests[%d] - token wrong. expected=%+v, got=%+v", name, input, i, want, got)
+				}
+				// The line of the last token is still available
+				start := strings.LastIndex(input[:want.Offset], "\n") + 1
+				if line := l.Slice(start, want.Offset); line != input[start:want.Offset] {
+					t.Fatalf("%v %q tests[%d] - Slice wrong. expected=%q, got=%q", name, input, i, input[start:want.Offset], line)
+				}
+				if want.Type == token.EOF {
+					break
+				}
+			}
+			if l.Err() != nil {
+				t.Fatalf("%v: Expected no error but got %v", name, l.Err())
+			}
+		}
+	}
+}
+
+func TestNewFromReaderBounded(t *testing.T) {
+	input := generateFeature(1000)
+	l, err := NewFromReader(strings.NewReader(input), "test.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Besides a chunk only the lines of the last tokens or the DocString
+	// being read are buffered
+	limit := bufferSize + len(generateFeature(1)) + 1
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if len(l.buf) > limit {
+			t.Fatalf("Buffer exceeded %v bytes at line %v, got %v", limit, tok.LineNumber, len(l.buf))
+		}
+	}
+}
+
+func TestNewFromReaderError(t *testing.T) {
+	// Reading the header fails after the first byte
+	header := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("# language: en\n")))
+	if _, err := NewFromReader(header, "test.feature"); err != iotest.ErrTimeout {
+		t.Fatalf("Expected %v but got %v", iotest.ErrTimeout, err)
+	}
+
+	// The first read of the feature returns the header, the second one fails
+	input := "Feature: a\n" + strings.Repeat("  Scenario: b\n", bufferSize)
+	l, err := NewFromReader(iotest.TimeoutReader(strings.NewReader(input)), "test.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+	if l.Err() != iotest.ErrTimeout {
+		t.Fatalf("Expected %v but got %v", iotest.ErrTimeout, l.Err())
+	}
+}
+
+// BenchmarkNewFromReader lexes growing inputs, the buffer-B metric is the
+// largest input held by the lexer at once which stays the same for the reader
+func BenchmarkNewFromReader(b *testing.B) {
+	for _, scenarios := range []int{10, 100, 1000, 10000} {
+		input := generateFeature(scenarios)
+		b.Run(fmt.Sprintf("New/scenarios=%d", scenarios), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				l := New(input)
+				for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+				}
+			}
+			b.ReportMetric(float64(len(input)), "buffer-B")
+		})
+		b.Run(fmt.Sprintf("Reader/scenarios=%d", scenarios), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+			buffer := 0
+			for i := 0; i < b.N; i++ {
+				l, _ := NewFromReader(strings.NewReader(input), "bench.feature")
+				for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+					if len(l.buf) > buffer {
+						buffer = len(l.buf)
+					}
+				}
+			}
+			b.ReportMetric(float64(buffer), "buffer-B")
+		})
+	}
+}
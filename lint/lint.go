@@ -0,0 +1,237 @@
+// Package lint checks the features for common mistakes
+//
+// Every check is a Rule with a name, the findings of all the rules are
+// reported along with the location they were found at.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dpakach/gorkin/object"
+)
+
+// Finding is a problem found in a feature
+type Finding struct {
+	URI        string
+	LineNumber int
+	Column     int
+	Rule       string
+	Message    string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%v:%v:%v: %v (%v)", f.URI, f.LineNumber, f.Column, f.Message, f.Rule)
+}
+
+// Rule is a single check run on every feature
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(feature *object.Feature) []Finding
+}
+
+// Rules are all the checks run by Feature and FeatureSet
+var Rules = []Rule{
+	{"feature-title", "features have a title", checkFeatureTitle},
+	{"empty-feature", "features have at least one scenario", checkEmptyFeature},
+	{"empty-scenario", "scenarios have at least one step", checkEmptyScenario},
+	{"duplicate-scenario", "scenario names are unique in a feature", checkDuplicateScenario},
+	{"missing-examples", "scenario outlines have at least one example row", checkMissingExamples},
+	{"undefined-placeholder", "placeholders are columns of every examples table", checkUndefinedPlaceholder},
+	{"unused-column", "examples columns are used by a placeholder", checkUnusedColumn},
+}
+
+// FeatureSet checks all the features in the FeatureSet
+func FeatureSet(fs *object.FeatureSet) []Finding {
+	var findings []Finding
+	for i := range fs.Features {
+		findings = append(findings, Feature(&fs.Features[i])...)
+	}
+	return findings
+}
+
+// Feature checks the feature with all the Rules, the findings are sorted by
+// their location
+func Feature(feature *object.Feature) []Finding {
+	var findings []Finding
+	for _, rule := range Rules {
+		for _, finding := range rule.Check(feature) {
+			finding.URI = feature.URI
+			finding.Rule = rule.Name
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].LineNumber != findings[j].LineNumber {
+			return findings[i].LineNumber < findings[j].LineNumber
+		}
+		return findings[i].Column < findings[j].Column
+	})
+	return findings
+}
+
+// scenarioTypes returns the scenarios of the feature and of its rules
+func scenarioTypes(feature *object.Feature) []object.ScenarioType {
+	scenarios := append([]object.ScenarioType{}, feature.Scenarios...)
+	for _, rule := range feature.Rules {
+		scenarios = append(scenarios, rule.Scenarios...)
+	}
+	return scenarios
+}
+
+// scenarioInfo returns the fields shared by scenarios and scenario outlines
+func scenarioInfo(scenarioType object.ScenarioType) (name string, steps []object.Step, line, column int) {
+	switch scenario := scenarioType.(type) {
+	case *object.Scenario:
+		return scenario.ScenarioText, scenario.Steps, scenario.LineNumber, scenario.Column
+	case *object.ScenarioOutline:
+		return scenario.ScenarioText, scenario.Steps, scenario.LineNumber, scenario.Column
+	}
+	return "", nil, 0, 0
+}
+
+func outlines(feature *object.Feature) []*object.ScenarioOutline {
+	var res []*object.ScenarioOutline
+	for _, scenarioType := range scenarioTypes(feature) {
+		if outline, ok := scenarioType.(*object.ScenarioOutline); ok {
+			res = append(res, outline)
+		}
+	}
+	return res
+}
+
+func checkFeatureTitle(feature *object.Feature) []Finding {
+	if strings.TrimSpace(feature.Title) != "" {
+		return nil
+	}
+	return []Finding{{LineNumber: feature.Token.LineNumber, Column: feature.Token.Column, Message: "feature has no title"}}
+}
+
+func checkEmptyFeature(feature *object.Feature) []Finding {
+	if len(scenarioTypes(feature)) != 0 {
+		return nil
+	}
+	return []Finding{{LineNumber: feature.Token.LineNumber, Column: feature.Token.Column, Message: "feature has no scenarios"}}
+}
+
+func checkEmptyScenario(feature *object.Feature) []Finding {
+	var findings []Finding
+	for _, scenarioType := range scenarioTypes(feature) {
+		name, steps, line, column := scenarioInfo(scenarioType)
+		if len(steps) == 0 {
+			findings = append(findings, Finding{LineNumber: line, Column: column, Message: fmt.Sprintf("scenario %q has no steps", name)})
+		}
+	}
+	return findings
+}
+
+func checkDuplicateScenario(feature *object.Feature) []Finding {
+	var findings []Finding
+	seen := map[string]int{}
+	for _, scenarioType := range scenarioTypes(feature) {
+		name, _, line, column := scenarioInfo(scenarioType)
+		if first, ok := seen[name]; ok {
+			findings = append(findings, Finding{LineNumber: line, Column: column, Message: fmt.Sprintf("scenario %q is already defined on line %v", name, first)})
+			continue
+		}
+		seen[name] = line
+	}
+	return findings
+}
+
+func checkMissingExamples(feature *object.Feature) []Finding {
+	var findings []Finding
+	for _, outline := range outlines(feature) {
+		rows := 0
+		for _, table := range outline.Tables {
+			if len(table) > 1 {
+				rows += len(table) - 1
+			}
+		}
+		if rows == 0 {
+			findings = append(findings, Finding{LineNumber: outline.LineNumber, Column: outline.Column, Message: fmt.Sprintf("scenario outline %q has no examples", outline.ScenarioText)})
+		}
+	}
+	return findings
+}
+
+// placeholders returns the names of the placeholders in the outline along
+// with the location of their first use
+func placeholders(outline *object.ScenarioOutline) ([]string, map[string][2]int) {
+	var names []string
+	locations := map[string][2]int{}
+	add := func(template object.Template, line, column int) {
+		for _, placeholder := range template.Placeholders {
+			if _, ok := locations[placeholder.Name]; !ok {
+				names = append(names, placeholder.Name)
+				locations[placeholder.Name] = [2]int{line, column}
+			}
+		}
+	}
+
+	index := outline.Placeholders()
+	add(index.Title, outline.LineNumber, outline.Column)
+	for i, step := range outline.Steps {
+		add(index.Steps[i].Text, step.LineNumber, step.Column)
+		for j, row := range index.Steps[i].Table {
+			for k, cell := range row {
+				add(cell, step.Table[j][k].LineNumber, step.Table[j][k].Column)
+			}
+		}
+		if step.DocString != nil {
+			add(index.Steps[i].DocString, step.DocString.LineNumber, step.DocString.Column)
+		}
+	}
+	return names, locations
+}
+
+func checkUndefinedPlaceholder(feature *object.Feature) []Finding {
+	var findings []Finding
+	for _, outline := range outlines(feature) {
+		names, locations := placeholders(outline)
+		for _, table := range outline.Tables {
+			if len(table) == 0 {
+				continue
+			}
+			columns := map[string]bool{}
+			for _, cell := range table[0] {
+				columns[cell.Literal] = true
+			}
+			for _, name := range names {
+				if !columns[name] {
+					location := locations[name]
+					findings = append(findings, Finding{
+						LineNumber: location[0],
+						Column:     location[1],
+						Message:    fmt.Sprintf("placeholder <%v> is not a column of the examples on line %v", name, table[0][0].LineNumber),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkUnusedColumn(feature *object.Feature) []Finding {
+	var findings []Finding
+	for _, outline := range outlines(feature) {
+		_, locations := placeholders(outline)
+		for _, table := range outline.Tables {
+			if len(table) == 0 {
+				continue
+			}
+			for _, cell := range table[0] {
+				if _, ok := locations[cell.Literal]; !ok {
+					findings = append(findings, Finding{
+						LineNumber: cell.LineNumber,
+						Column:     cell.Column,
+						Message:    fmt.Sprintf("examples column %q is not used by any placeholder", cell.Literal),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
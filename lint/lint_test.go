@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+func parseFeatureSet(t *testing.T, input string) *object.FeatureSet {
+	l := lexer.New(input)
+	l.FilePath = "test.feature"
+	p := parser.New(l)
+	fs := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatal(p.Errors()[0].GetMessage())
+	}
+	return fs
+}
+
+func TestFeatureSet(t *testing.T) {
+	input := `Feature: lint
+
+  Scenario: twice
+    Given a step
+
+  Scenario: twice
+    Given a step
+
+  Scenario Outline: eating <food>
+    Given I eat <count> <food>
+
+    Examples:
+      | food   | count | unused |
+      | apple  | 1     | x      |
+
+    Examples:
+      | food   |
+      | banana |
+
+  Scenario Outline: nothing <x>
+    Given a step
+
+    Examples:
+      | x |
+`
+
+	expected := []string{
+		"test.feature:6:3: scenario \"twice\" is already defined on line 3 (duplicate-scenario)",
+		"test.feature:10:5: placeholder <count> is not a column of the examples on line 17 (undefined-placeholder)",
+		"test.feature:13:26: examples column \"unused\" is not used by any placeholder (unused-column)",
+		"test.feature:20:3: scenario outline \"nothing <x>\" has no examples (missing-examples)",
+	}
+
+	findings := FeatureSet(parseFeatureSet(t, input))
+	if len(findings) != len(expected) {
+		t.Fatalf("Findings length mismatch, expected %v, got %v: %v", len(expected), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding.String() != expected[i] {
+			t.Fatalf("Finding mismatch, expected %q, got %q", expected[i], finding.String())
+		}
+	}
+}
+
+func TestFeatureWithoutTitleAndScenarios(t *testing.T) {
+	findings := FeatureSet(parseFeatureSet(t, "Feature:\n"))
+
+	expected := []string{"feature-title", "empty-feature"}
+	if len(findings) != len(expected) {
+		t.Fatalf("Findings length mismatch, expected %v, got %v: %v", len(expected), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding.Rule != expected[i] {
+			t.Fatalf("Rule mismatch, expected %v, got %v", expected[i], finding.Rule)
+		}
+	}
+}
+
+func TestScenarioWithoutSteps(t *testing.T) {
+	feature := &object.Feature{
+		URI:   "test.feature",
+		Title: "steps",
+		Rules: []object.Rule{{
+			Title:     "empty",
+			Scenarios: []object.ScenarioType{&object.Scenario{ScenarioText: "no steps", LineNumber: 4, Column: 5}},
+		}},
+	}
+
+	findings := Feature(feature)
+	expected := "test.feature:4:5: scenario \"no steps\" has no steps (empty-scenario)"
+	if len(findings) != 1 || findings[0].String() != expected {
+		t.Fatalf("Expected the finding %q but got %v", expected, findings)
+	}
+}
+
+func TestCleanFeature(t *testing.T) {
+	input := `Feature: clean
+
+  Scenario Outline: eating <food>
+    Given I eat <food>
+      """
+      <food>
+      """
+
+    Examples:
+      | food  |
+      | apple |
+`
+	if findings := FeatureSet(parseFeatureSet(t, input)); len(findings) != 0 {
+		t.Fatalf("Expected no findings but got %v", findings)
+	}
+}
@@ -0,0 +1,282 @@
+package messages
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/token"
+)
+
+// FeatureSetEnvelopes converts every feature in the FeatureSet into its
+// source, gherkinDocument and pickle envelopes, the sources are read from
+// the URI of the features
+func FeatureSetEnvelopes(fs *object.FeatureSet, newID IDGenerator) ([]*Envelope, error) {
+	var envelopes []*Envelope
+	for i := range fs.Features {
+		feature := &fs.Features[i]
+		source, err := ioutil.ReadFile(feature.URI)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, FeatureEnvelopes(feature, string(source), newID)...)
+	}
+	return envelopes, nil
+}
+
+// WriteFeatureSet streams the envelopes of every feature in the FeatureSet
+// to out as NDJSON, the envelopes of a feature are written as soon as the
+// feature is converted
+func WriteFeatureSet(out io.Writer, fs *object.FeatureSet, newID IDGenerator) error {
+	for i := range fs.Features {
+		feature := &fs.Features[i]
+		source, err := ioutil.ReadFile(feature.URI)
+		if err != nil {
+			return err
+		}
+		if err := WriteNDJSON(out, FeatureEnvelopes(feature, string(source), newID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FeatureEnvelopes converts a feature parsed from given source into its
+// source, gherkinDocument and pickle envelopes
+func FeatureEnvelopes(feature *object.Feature, source string, newID IDGenerator) []*Envelope {
+	c := &converter{newID: newID, lines: strings.Split(source, "\n")}
+	document := c.gherkinDocument(feature)
+
+	envelopes := []*Envelope{
+		{Source: &Source{URI: feature.URI, Data: source, MediaType: MediaType}},
+		{GherkinDocument: document},
+	}
+	for _, pickle := range c.pickles(feature) {
+		envelopes = append(envelopes, &Envelope{Pickle: pickle})
+	}
+	return envelopes
+}
+
+type converter struct {
+	newID IDGenerator
+	lines []string
+	nodes map[location]string
+}
+
+func (c *converter) gherkinDocument(feature *object.Feature) *GherkinDocument {
+	document := &GherkinDocument{URI: feature.URI, Comments: []Comment{}}
+	for _, comment := range feature.Comments {
+		document.Comments = append(document.Comments, Comment{
+			Location: Location{Line: comment.LineNumber, Column: 1},
+			Text:     strings.TrimRight(c.line(comment.LineNumber), " \t\r"),
+		})
+	}
+
+	document.Feature = &Feature{
+		Location:    tokenLocation(feature.Token),
+		Tags:        c.tags(feature.TagTokens, feature.Tags),
+		Language:    feature.Language,
+		Keyword:     feature.Token.Literal,
+		Name:        feature.Title,
+		Description: feature.Description,
+		Children:    []FeatureChild{},
+	}
+	if feature.Language == "" {
+		document.Feature.Language = token.DefaultLanguage
+	}
+	if feature.Background != nil {
+		document.Feature.Children = append(document.Feature.Children, FeatureChild{Background: c.background(feature.Background)})
+	}
+	for _, scenario := range feature.Scenarios {
+		document.Feature.Children = append(document.Feature.Children, FeatureChild{Scenario: c.scenario(scenario)})
+	}
+	for i := range feature.Rules {
+		document.Feature.Children = append(document.Feature.Children, FeatureChild{Rule: c.rule(&feature.Rules[i])})
+	}
+	return document
+}
+
+func (c *converter) rule(rule *object.Rule) *Rule {
+	r := &Rule{
+		Location:    tokenLocation(rule.Token),
+		Tags:        c.tags(rule.TagTokens, rule.Tags),
+		Keyword:     rule.Token.Literal,
+		Name:        rule.Title,
+		Description: rule.Description,
+		Children:    []RuleChild{},
+	}
+	if rule.Background != nil {
+		r.Children = append(r.Children, RuleChild{Background: c.background(rule.Background)})
+	}
+	for _, scenario := range rule.Scenarios {
+		r.Children = append(r.Children, RuleChild{Scenario: c.scenario(scenario)})
+	}
+	r.ID = c.newID()
+	return r
+}
+
+func (c *converter) background(background *object.Background) *Background {
+	b := &Background{
+		Location: Location{Line: background.LineNumber, Column: background.Column},
+		Keyword:  background.Keyword,
+		Name:     background.Title,
+		Steps:    c.steps(background.Steps),
+	}
+	b.ID = c.newID()
+	return b
+}
+
+func (c *converter) scenario(scenarioType object.ScenarioType) *Scenario {
+	s := &Scenario{Examples: []Examples{}}
+	switch scenario := scenarioType.(type) {
+	case *object.Scenario:
+		s.Location = Location{Line: scenario.LineNumber, Column: scenario.Column}
+		s.Tags = c.tags(scenario.TagTokens, scenario.Tags)
+		s.Keyword = scenario.Keyword
+		s.Name = scenario.ScenarioText
+		s.Description = scenario.Description
+		s.Steps = c.steps(scenario.Steps)
+	case *object.ScenarioOutline:
+		s.Location = Location{Line: scenario.LineNumber, Column: scenario.Column}
+		s.Tags = c.tags(scenario.TagTokens, scenario.Tags)
+		s.Keyword = scenario.Keyword
+		s.Name = scenario.ScenarioText
+		s.Description = scenario.Description
+		s.Steps = c.steps(scenario.Steps)
+		for i, table := range scenario.Tables {
+			s.Examples = append(s.Examples, c.examples(scenario, i, table))
+		}
+	}
+	s.ID = c.nodeID(s.Location.Line, s.Location.Column)
+	return s
+}
+
+func (c *converter) examples(outline *object.ScenarioOutline, i int, table object.Table) Examples {
+	var e Examples
+	if i < len(outline.TableTokens) {
+		e.Location = tokenLocation(outline.TableTokens[i])
+		e.Keyword = outline.TableTokens[i].Literal
+	}
+	var tagTokens []token.Token
+	var tags []string
+	if i < len(outline.TableTagTokens) {
+		tagTokens = outline.TableTagTokens[i]
+	}
+	if i < len(outline.TableTags) {
+		tags = outline.TableTags[i]
+	}
+	e.Tags = c.tags(tagTokens, tags)
+	if i < len(outline.TableNames) {
+		e.Name = outline.TableNames[i]
+	}
+	if i < len(outline.TableDescriptions) {
+		e.Description = outline.TableDescriptions[i]
+	}
+
+	rows := c.tableRows(table)
+	e.TableBody = []TableRow{}
+	if len(rows) > 0 {
+		e.TableHeader = &rows[0]
+		e.TableBody = rows[1:]
+	}
+	e.ID = c.newID()
+	return e
+}
+
+// tags converts the tag tokens into Tag nodes, when the tokens are missing
+// the tag names are used without their locations
+func (c *converter) tags(tokens []token.Token, names []string) []Tag {
+	tags := []Tag{}
+	if len(tokens) != len(names) {
+		for _, name := range names {
+			tags = append(tags, Tag{Name: "@" + name, ID: c.newID()})
+		}
+		return tags
+	}
+	for _, tag := range tokens {
+		tags = append(tags, Tag{Location: tokenLocation(tag), Name: "@" + tag.Literal, ID: c.nodeID(tag.LineNumber, tag.Column)})
+	}
+	return tags
+}
+
+func (c *converter) steps(steps []object.Step) []Step {
+	res := []Step{}
+	for _, step := range steps {
+		s := Step{
+			Location:    Location{Line: step.LineNumber, Column: step.Column},
+			Keyword:     step.Token.Literal + " ",
+			KeywordType: keywordType(step.Token.Type),
+			Text:        step.Text,
+		}
+		if step.DocString != nil {
+			// The DocStrings built without the parser have no delimiter
+			delimiter := step.DocString.Delimiter
+			if delimiter == "" {
+				delimiter = `"""`
+			}
+			s.DocString = &DocString{
+				Location:  Location{Line: step.DocString.LineNumber, Column: step.DocString.Column},
+				MediaType: step.DocString.MediaType,
+				Content:   step.DocString.Content,
+				Delimiter: delimiter,
+			}
+		}
+		if rows := c.tableRows(step.Table); len(rows) > 0 {
+			s.DataTable = &DataTable{Location: rows[0].Location, Rows: rows}
+		}
+		s.ID = c.nodeID(step.LineNumber, step.Column)
+		res = append(res, s)
+	}
+	return res
+}
+
+func (c *converter) tableRows(table object.Table) []TableRow {
+	rows := []TableRow{}
+	for _, row := range table {
+		if len(row) == 0 {
+			continue
+		}
+		r := TableRow{Cells: []TableCell{}}
+		line := row[0].LineNumber
+		r.Location = Location{Line: line, Column: 1}
+		if i := strings.Index(c.line(line), "|"); i >= 0 {
+			r.Location.Column = utf8.RuneCountInString(c.line(line)[:i]) + 1
+		}
+		for _, cell := range row {
+			r.Cells = append(r.Cells, TableCell{
+				Location: Location{Line: cell.LineNumber, Column: cell.Column},
+				Value:    cell.Literal,
+			})
+		}
+		r.ID = c.nodeID(row[0].LineNumber, row[0].Column)
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+func (c *converter) line(lineNumber int) string {
+	if lineNumber < 1 || lineNumber > len(c.lines) {
+		return ""
+	}
+	return c.lines[lineNumber-1]
+}
+
+func tokenLocation(t token.Token) Location {
+	return Location{Line: t.LineNumber, Column: t.Column}
+}
+
+func keywordType(t token.Type) string {
+	switch t {
+	case token.GIVEN:
+		return "Context"
+	case token.WHEN:
+		return "Action"
+	case token.THEN:
+		return "Outcome"
+	case token.AND, token.BUT:
+		return "Conjunction"
+	}
+	return "Unknown"
+}
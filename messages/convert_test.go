@@ -0,0 +1,210 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+const source = `# language: en
+@featureTag
+Feature: messages
+  Some description
+
+  Background:
+    Given a background step
+
+  # a comment
+  @scenarioTag
+  Scenario: a "quoted" scenario
+    When I have a table
+      | a | b |
+      | 1 | 2 |
+    Then I have a doc string
+      """
+      some doc
+      """
+
+  Scenario Outline: eat <count> things
+    When I eat <count> things
+
+    @examplesTag
+    Examples:
+      | count |
+      | 5     |
+      | 12    |
+
+  @ruleTag
+  Rule: a rule
+    Background:
+      Given a rule background step
+
+    Scenario: rule scenario
+      Then it passes
+`
+
+func parseFeature(t *testing.T, input string) *object.Feature {
+	l := lexer.New(input)
+	l.FilePath = "features/messages.feature"
+	p := parser.New(l)
+	featureSet := p.Parse()
+	for _, err := range p.Errors() {
+		t.Error(err.GetMessage())
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+	return &featureSet.Features[0]
+}
+
+func TestFeatureEnvelopes(t *testing.T) {
+	envelopes := FeatureEnvelopes(parseFeature(t, source), source, NewIncrementingIDGenerator())
+
+	if len(envelopes) != 6 {
+		t.Fatalf("Envelope count mismatch, expected %v, got %v", 6, len(envelopes))
+	}
+	if envelopes[0].Source == nil || envelopes[0].Source.Data != source || envelopes[0].Source.MediaType != MediaType {
+		t.Fatalf("Expected source envelope but got %+v", envelopes[0])
+	}
+	document := envelopes[1].GherkinDocument
+	if document == nil {
+		t.Fatalf("Expected gherkinDocument envelope but got %+v", envelopes[1])
+	}
+	if document.URI != "features/messages.feature" {
+		t.Fatalf("URI mismatch, expected %v, got %v", "features/messages.feature", document.URI)
+	}
+
+	expectedComments := []Comment{
+		{Location{1, 1}, "# language: en"},
+		{Location{9, 1}, "  # a comment"},
+	}
+	if len(document.Comments) != len(expectedComments) {
+		t.Fatalf("Comments mismatch, expected %v, got %v", expectedComments, document.Comments)
+	}
+	for i, comment := range expectedComments {
+		if document.Comments[i] != comment {
+			t.Fatalf("Comment mismatch, expected %v, got %v", comment, document.Comments[i])
+		}
+	}
+
+	feature := document.Feature
+	if feature.Location != (Location{3, 1}) || feature.Keyword != "Feature" || feature.Name != "messages" || feature.Language != "en" {
+		t.Fatalf("Feature mismatch, got %+v", feature)
+	}
+	if feature.Description != "  Some description" {
+		t.Fatalf("Description mismatch, expected %q, got %q", "  Some description", feature.Description)
+	}
+	if len(feature.Tags) != 1 || feature.Tags[0].Name != "@featureTag" || feature.Tags[0].Location != (Location{2, 1}) {
+		t.Fatalf("Feature tags mismatch, got %+v", feature.Tags)
+	}
+	if len(feature.Children) != 4 {
+		t.Fatalf("Children count mismatch, expected %v, got %v", 4, len(feature.Children))
+	}
+
+	background := feature.Children[0].Background
+	if background == nil || background.Location != (Location{6, 3}) || background.Keyword != "Background" {
+		t.Fatalf("Background mismatch, got %+v", background)
+	}
+
+	scenario := feature.Children[1].Scenario
+	if scenario.Name != `a "quoted" scenario` || scenario.Keyword != "Scenario" || scenario.Location != (Location{11, 3}) {
+		t.Fatalf("Scenario mismatch, got %+v", scenario)
+	}
+	if len(scenario.Tags) != 1 || scenario.Tags[0].Location != (Location{10, 3}) {
+		t.Fatalf("Scenario tags mismatch, got %+v", scenario.Tags)
+	}
+	step := scenario.Steps[0]
+	if step.Keyword != "When " || step.KeywordType != "Action" || step.Text != "I have a table" || step.Location != (Location{12, 5}) {
+		t.Fatalf("Step mismatch, got %+v", step)
+	}
+	if step.DataTable == nil || step.DataTable.Location != (Location{13, 7}) || len(step.DataTable.Rows) != 2 {
+		t.Fatalf("DataTable mismatch, got %+v", step.DataTable)
+	}
+	cell := step.DataTable.Rows[1].Cells[1]
+	if cell.Value != "2" || cell.Location != (Location{14, 13}) {
+		t.Fatalf("Cell mismatch, got %+v", cell)
+	}
+	docString := scenario.Steps[1].DocString
+	if docString == nil || docString.Content != "some doc" || docString.Location != (Location{16, 7}) || docString.Delimiter != `"""` {
+		t.Fatalf("DocString mismatch, got %+v", docString)
+	}
+
+	outline := feature.Children[2].Scenario
+	if outline.Keyword != "Scenario Outline" || outline.Name != "eat <count> things" || len(outline.Examples) != 1 {
+		t.Fatalf("Outline mismatch, got %+v", outline)
+	}
+	examples := outline.Examples[0]
+	if examples.Keyword != "Examples" || examples.Location != (Location{24, 5}) {
+		t.Fatalf("Examples mismatch, got %+v", examples)
+	}
+	if len(examples.Tags) != 1 || examples.Tags[0].Name != "@examplesTag" || examples.Tags[0].Location != (Location{23, 5}) {
+		t.Fatalf("Examples tags mismatch, got %+v", examples.Tags)
+	}
+	if examples.TableHeader == nil || examples.TableHeader.Cells[0].Value != "count" || len(examples.TableBody) != 2 {
+		t.Fatalf("Examples table mismatch, got %+v", examples)
+	}
+
+	rule := feature.Children[3].Rule
+	if rule == nil || rule.Name != "a rule" || rule.Keyword != "Rule" || rule.Location != (Location{30, 3}) {
+		t.Fatalf("Rule mismatch, got %+v", rule)
+	}
+	if len(rule.Children) != 2 || rule.Children[0].Background == nil || rule.Children[1].Scenario == nil {
+		t.Fatalf("Rule children mismatch, got %+v", rule.Children)
+	}
+}
+
+func TestFeatureEnvelopesIDs(t *testing.T) {
+	envelopes := FeatureEnvelopes(parseFeature(t, source), source, NewIncrementingIDGenerator())
+
+	ids := map[string]bool{}
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if id, ok := v["id"].(string); ok {
+				if ids[id] {
+					t.Fatalf("Duplicate id %q", id)
+				}
+				ids[id] = true
+			}
+			for _, value := range v {
+				collect(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				collect(value)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteNDJSON(&out, envelopes); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(envelopes) {
+		t.Fatalf("Line count mismatch, expected %v, got %v", len(envelopes), len(lines))
+	}
+	for _, line := range lines {
+		var v interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		collect(v)
+	}
+
+	// Converting again gives the same output
+	var again bytes.Buffer
+	envelopes = FeatureEnvelopes(parseFeature(t, source), source, NewIncrementingIDGenerator())
+	if err := WriteNDJSON(&again, envelopes); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != out.String() {
+		t.Fatal("Expected the same output for the same feature")
+	}
+}
@@ -0,0 +1,248 @@
+// Package messages converts parsed features into Cucumber Messages
+//
+// Only the envelopes produced while parsing are supported, that is the
+// source, gherkinDocument and pickle envelopes. The field names follow the
+// JSON schema of the Cucumber Messages protocol so the output can be read by
+// the other tools that consume the protocol.
+package messages
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// MediaType is the media type of the Gherkin sources
+const MediaType = "text/x.cucumber.gherkin+plain"
+
+// Envelope wraps a single message, only one of the fields is set
+type Envelope struct {
+	Source          *Source          `json:"source,omitempty"`
+	GherkinDocument *GherkinDocument `json:"gherkinDocument,omitempty"`
+	Pickle          *Pickle          `json:"pickle,omitempty"`
+}
+
+// Source is the raw content of a feature file
+type Source struct {
+	URI       string `json:"uri"`
+	Data      string `json:"data"`
+	MediaType string `json:"mediaType"`
+}
+
+// Location is a position in a source, both the line and the column are 1 based
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+// GherkinDocument is the AST of a feature file
+type GherkinDocument struct {
+	URI      string    `json:"uri,omitempty"`
+	Feature  *Feature  `json:"feature,omitempty"`
+	Comments []Comment `json:"comments"`
+}
+
+// Comment is a comment line in a feature file
+type Comment struct {
+	Location Location `json:"location"`
+	Text     string   `json:"text"`
+}
+
+// Feature is the Feature node of the GherkinDocument
+type Feature struct {
+	Location    Location       `json:"location"`
+	Tags        []Tag          `json:"tags"`
+	Language    string         `json:"language"`
+	Keyword     string         `json:"keyword"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Children    []FeatureChild `json:"children"`
+}
+
+// FeatureChild is a Rule, a Background or a Scenario inside a Feature
+type FeatureChild struct {
+	Rule       *Rule       `json:"rule,omitempty"`
+	Background *Background `json:"background,omitempty"`
+	Scenario   *Scenario   `json:"scenario,omitempty"`
+}
+
+// Rule is the Rule node of the GherkinDocument
+type Rule struct {
+	Location    Location    `json:"location"`
+	Tags        []Tag       `json:"tags"`
+	Keyword     string      `json:"keyword"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Children    []RuleChild `json:"children"`
+	ID          string      `json:"id"`
+}
+
+// RuleChild is a Background or a Scenario inside a Rule
+type RuleChild struct {
+	Background *Background `json:"background,omitempty"`
+	Scenario   *Scenario   `json:"scenario,omitempty"`
+}
+
+// Background is the Background node of the GherkinDocument
+type Background struct {
+	Location    Location `json:"location"`
+	Keyword     string   `json:"keyword"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Steps       []Step   `json:"steps"`
+	ID          string   `json:"id"`
+}
+
+// Scenario is the Scenario node of the GherkinDocument, scenario outlines
+// are scenarios with Examples
+type Scenario struct {
+	Location    Location   `json:"location"`
+	Tags        []Tag      `json:"tags"`
+	Keyword     string     `json:"keyword"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Steps       []Step     `json:"steps"`
+	Examples    []Examples `json:"examples"`
+	ID          string     `json:"id"`
+}
+
+// Examples is an Examples block of a scenario outline
+type Examples struct {
+	Location    Location   `json:"location"`
+	Tags        []Tag      `json:"tags"`
+	Keyword     string     `json:"keyword"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	TableHeader *TableRow  `json:"tableHeader,omitempty"`
+	TableBody   []TableRow `json:"tableBody"`
+	ID          string     `json:"id"`
+}
+
+// Tag is a tag of a Feature, Rule, Scenario or Examples, the name includes
+// the leading @
+type Tag struct {
+	Location Location `json:"location"`
+	Name     string   `json:"name"`
+	ID       string   `json:"id"`
+}
+
+// Step is a Step node of the GherkinDocument
+type Step struct {
+	Location    Location   `json:"location"`
+	Keyword     string     `json:"keyword"`
+	KeywordType string     `json:"keywordType,omitempty"`
+	Text        string     `json:"text"`
+	DocString   *DocString `json:"docString,omitempty"`
+	DataTable   *DataTable `json:"dataTable,omitempty"`
+	ID          string     `json:"id"`
+}
+
+// DocString is the DocString argument of a Step
+type DocString struct {
+	Location  Location `json:"location"`
+	MediaType string   `json:"mediaType,omitempty"`
+	Content   string   `json:"content"`
+	Delimiter string   `json:"delimiter"`
+}
+
+// DataTable is the table argument of a Step
+type DataTable struct {
+	Location Location   `json:"location"`
+	Rows     []TableRow `json:"rows"`
+}
+
+// TableRow is a row of a DataTable or an Examples table
+type TableRow struct {
+	Location Location    `json:"location"`
+	Cells    []TableCell `json:"cells"`
+	ID       string      `json:"id"`
+}
+
+// TableCell is a cell of a TableRow
+type TableCell struct {
+	Location Location `json:"location"`
+	Value    string   `json:"value"`
+}
+
+// Pickle is a compiled Scenario ready to be executed, with the background
+// steps included and the outline placeholders substituted
+type Pickle struct {
+	ID         string       `json:"id"`
+	URI        string       `json:"uri"`
+	Name       string       `json:"name"`
+	Language   string       `json:"language"`
+	Steps      []PickleStep `json:"steps"`
+	Tags       []PickleTag  `json:"tags"`
+	AstNodeIds []string     `json:"astNodeIds"`
+}
+
+// PickleStep is a Step of a Pickle
+type PickleStep struct {
+	Argument   *PickleStepArgument `json:"argument,omitempty"`
+	AstNodeIds []string            `json:"astNodeIds"`
+	ID         string              `json:"id"`
+	Type       string              `json:"type,omitempty"`
+	Text       string              `json:"text"`
+}
+
+// PickleStepArgument is the DocString or the DataTable of a PickleStep
+type PickleStepArgument struct {
+	DocString *PickleDocString `json:"docString,omitempty"`
+	DataTable *PickleTable     `json:"dataTable,omitempty"`
+}
+
+// PickleDocString is the DocString argument of a PickleStep
+type PickleDocString struct {
+	MediaType string `json:"mediaType,omitempty"`
+	Content   string `json:"content"`
+}
+
+// PickleTable is the table argument of a PickleStep
+type PickleTable struct {
+	Rows []PickleTableRow `json:"rows"`
+}
+
+// PickleTableRow is a row of a PickleTable
+type PickleTableRow struct {
+	Cells []PickleTableCell `json:"cells"`
+}
+
+// PickleTableCell is a cell of a PickleTableRow
+type PickleTableCell struct {
+	Value string `json:"value"`
+}
+
+// PickleTag is a tag of a Pickle along with the ID of the Tag node it came from
+type PickleTag struct {
+	Name      string `json:"name"`
+	AstNodeID string `json:"astNodeId"`
+}
+
+// IDGenerator returns a new unique ID on every call
+type IDGenerator func() string
+
+// NewIncrementingIDGenerator creates an IDGenerator returning "0", "1", "2"...
+//
+// The IDs only depend on the order of the calls, so converting the same
+// features always gives the same IDs
+func NewIncrementingIDGenerator() IDGenerator {
+	next := 0
+	return func() string {
+		id := strconv.Itoa(next)
+		next++
+		return id
+	}
+}
+
+// WriteNDJSON writes the envelopes to out as newline delimited JSON, one
+// envelope per line
+func WriteNDJSON(out io.Writer, envelopes []*Envelope) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for _, envelope := range envelopes {
+		if err := encoder.Encode(envelope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package messages
+
+import (
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/pickles"
+	"github.com/dpakach/gorkin/token"
+)
+
+type location struct {
+	line, column int
+}
+
+// nodeID records the ID of the AST node at given location so the pickles
+// can point back to it
+func (c *converter) nodeID(line, column int) string {
+	id := c.newID()
+	if c.nodes == nil {
+		c.nodes = map[location]string{}
+	}
+	c.nodes[location{line, column}] = id
+	return id
+}
+
+// pickles compiles the scenarios of the feature into pickles, the pickles
+// point back to the nodes of the GherkinDocument converted before
+func (c *converter) pickles(feature *object.Feature) []*Pickle {
+	compiler := &pickles.Compiler{
+		NewID: c.newID,
+		NodeID: func(line, column int) string {
+			return c.nodes[location{line, column}]
+		},
+	}
+
+	// The pickles are still compiled for the placeholders missing from the
+	// examples, pickles.Compile reports those
+	compiledPickles, _ := compiler.Compile(feature)
+	var res []*Pickle
+	for _, compiled := range compiledPickles {
+		pickle := &Pickle{
+			ID:         compiled.ID,
+			URI:        compiled.URI,
+			Name:       compiled.Name,
+			Language:   compiled.Language,
+			Steps:      []PickleStep{},
+			Tags:       []PickleTag{},
+			AstNodeIds: compiled.AstNodeIds,
+		}
+		if pickle.Language == "" {
+			pickle.Language = token.DefaultLanguage
+		}
+		for _, step := range compiled.Steps {
+			pickle.Steps = append(pickle.Steps, pickleStep(step))
+		}
+		for _, tag := range compiled.Tags {
+			pickle.Tags = append(pickle.Tags, PickleTag{Name: "@" + tag.Name, AstNodeID: tag.AstNodeID})
+		}
+		res = append(res, pickle)
+	}
+	return res
+}
+
+func pickleStep(step pickles.Step) PickleStep {
+	pickleStep := PickleStep{
+		AstNodeIds: step.AstNodeIds,
+		ID:         step.ID,
+		Type:       step.Type,
+		Text:       step.Text,
+	}
+	if step.DocString != nil {
+		pickleStep.Argument = &PickleStepArgument{DocString: &PickleDocString{MediaType: step.DocString.MediaType, Content: step.DocString.Content}}
+	}
+	if len(step.Table) > 0 {
+		table := &PickleTable{Rows: []PickleTableRow{}}
+		for _, row := range step.Table {
+			pickleRow := PickleTableRow{Cells: []PickleTableCell{}}
+			for _, cell := range row {
+				pickleRow.Cells = append(pickleRow.Cells, PickleTableCell{Value: cell.Literal})
+			}
+			table.Rows = append(table.Rows, pickleRow)
+		}
+		pickleStep.Argument = &PickleStepArgument{DataTable: table}
+	}
+	return pickleStep
+}
@@ -0,0 +1,136 @@
+package messages
+
+import (
+	"testing"
+)
+
+func findIDs(document *GherkinDocument) map[string]string {
+	ids := map[string]string{}
+	for _, child := range document.Feature.Children {
+		if child.Scenario != nil {
+			ids[child.Scenario.Name] = child.Scenario.ID
+			for _, examples := range child.Scenario.Examples {
+				for _, row := range examples.TableBody {
+					ids[row.Cells[0].Value] = row.ID
+				}
+			}
+		}
+		if child.Rule != nil {
+			for _, ruleChild := range child.Rule.Children {
+				if ruleChild.Scenario != nil {
+					ids[ruleChild.Scenario.Name] = ruleChild.Scenario.ID
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func TestPickles(t *testing.T) {
+	envelopes := FeatureEnvelopes(parseFeature(t, source), source, NewIncrementingIDGenerator())
+	ids := findIDs(envelopes[1].GherkinDocument)
+
+	var pickles []*Pickle
+	for _, envelope := range envelopes[2:] {
+		if envelope.Pickle == nil {
+			t.Fatalf("Expected pickle envelope but got %+v", envelope)
+		}
+		pickles = append(pickles, envelope.Pickle)
+	}
+
+	expected := []struct {
+		name       string
+		steps      []string
+		types      []string
+		tags       []string
+		astNodeIds []string
+	}{
+		{
+			`a "quoted" scenario`,
+			[]string{"a background step", "I have a table", "I have a doc string"},
+			[]string{"Context", "Action", "Outcome"},
+			[]string{"@featureTag", "@scenarioTag"},
+			[]string{ids[`a "quoted" scenario`]},
+		},
+		{
+			"eat 5 things",
+			[]string{"a background step", "I eat 5 things"},
+			[]string{"Context", "Action"},
+			[]string{"@featureTag", "@examplesTag"},
+			[]string{ids["eat <count> things"], ids["5"]},
+		},
+		{
+			"eat 12 things",
+			[]string{"a background step", "I eat 12 things"},
+			[]string{"Context", "Action"},
+			[]string{"@featureTag", "@examplesTag"},
+			[]string{ids["eat <count> things"], ids["12"]},
+		},
+		{
+			"rule scenario",
+			[]string{"a background step", "a rule background step", "it passes"},
+			[]string{"Context", "Context", "Outcome"},
+			[]string{"@featureTag", "@ruleTag"},
+			[]string{ids["rule scenario"]},
+		},
+	}
+
+	if len(pickles) != len(expected) {
+		t.Fatalf("Pickle count mismatch, expected %v, got %v", len(expected), len(pickles))
+	}
+	for i, tt := range expected {
+		pickle := pickles[i]
+		if pickle.Name != tt.name {
+			t.Fatalf("Name mismatch, expected %q, got %q", tt.name, pickle.Name)
+		}
+		if pickle.URI != "features/messages.feature" || pickle.Language != "en" {
+			t.Fatalf("Pickle mismatch, got %+v", pickle)
+		}
+		var steps, types []string
+		for _, step := range pickle.Steps {
+			steps = append(steps, step.Text)
+			types = append(types, step.Type)
+		}
+		if !areArrayEqual(steps, tt.steps) {
+			t.Fatalf("Steps mismatch, expected %q, got %q", tt.steps, steps)
+		}
+		if !areArrayEqual(types, tt.types) {
+			t.Fatalf("Step types mismatch, expected %q, got %q", tt.types, types)
+		}
+		var tags []string
+		for _, tag := range pickle.Tags {
+			tags = append(tags, tag.Name)
+		}
+		if !areArrayEqual(tags, tt.tags) {
+			t.Fatalf("Tags mismatch, expected %q, got %q", tt.tags, tags)
+		}
+		if !areArrayEqual(pickle.AstNodeIds, tt.astNodeIds) {
+			t.Fatalf("AstNodeIds mismatch, expected %q, got %q", tt.astNodeIds, pickle.AstNodeIds)
+		}
+	}
+
+	table := pickles[0].Steps[1].Argument.DataTable
+	if table == nil || len(table.Rows) != 2 || table.Rows[1].Cells[1].Value != "2" {
+		t.Fatalf("DataTable argument mismatch, got %+v", pickles[0].Steps[1].Argument)
+	}
+	docString := pickles[0].Steps[2].Argument.DocString
+	if docString == nil || docString.Content != "some doc" {
+		t.Fatalf("DocString argument mismatch, got %+v", pickles[0].Steps[2].Argument)
+	}
+	outlineStep := pickles[1].Steps[1]
+	if len(outlineStep.AstNodeIds) != 2 || outlineStep.AstNodeIds[1] != ids["5"] {
+		t.Fatalf("Outline step AstNodeIds mismatch, got %q", outlineStep.AstNodeIds)
+	}
+}
+
+func areArrayEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
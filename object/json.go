@@ -0,0 +1,527 @@
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dpakach/gorkin/token"
+)
+
+// SchemaVersion is the version of the JSON Schema in schema/ the features are
+// marshalled with, the major version changes with every incompatible change
+const SchemaVersion = "1.0"
+
+// Types of the scenarios in the JSON documents
+const (
+	jsonScenario        = "scenario"
+	jsonScenarioOutline = "scenarioOutline"
+)
+
+type jsonFeatureSet struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	Features      []json.RawMessage `json:"features"`
+}
+
+type jsonLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonTag struct {
+	Name     string        `json:"name"`
+	Location *jsonLocation `json:"location,omitempty"`
+}
+
+type jsonComment struct {
+	Text     string       `json:"text"`
+	Location jsonLocation `json:"location"`
+}
+
+type jsonFeature struct {
+	URI         string            `json:"uri"`
+	Language    string            `json:"language"`
+	Keyword     string            `json:"keyword"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Location    jsonLocation      `json:"location"`
+	Tags        []jsonTag         `json:"tags"`
+	Background  *jsonBackground   `json:"background,omitempty"`
+	Scenarios   []json.RawMessage `json:"scenarios"`
+	Rules       []jsonRule        `json:"rules"`
+	Comments    []jsonComment     `json:"comments"`
+}
+
+type jsonRule struct {
+	Keyword     string            `json:"keyword"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Location    jsonLocation      `json:"location"`
+	Tags        []jsonTag         `json:"tags"`
+	Background  *jsonBackground   `json:"background,omitempty"`
+	Scenarios   []json.RawMessage `json:"scenarios"`
+}
+
+type jsonBackground struct {
+	Keyword  string       `json:"keyword"`
+	Title    string       `json:"title"`
+	Location jsonLocation `json:"location"`
+	Steps    []jsonStep   `json:"steps"`
+}
+
+// jsonScenarioType is a scenario or a scenario outline, Type tells them
+// apart and Examples is only used by the outlines
+type jsonScenarioType struct {
+	Type        string         `json:"type"`
+	Keyword     string         `json:"keyword"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Location    jsonLocation   `json:"location"`
+	Tags        []jsonTag      `json:"tags"`
+	Steps       []jsonStep     `json:"steps"`
+	Examples    []jsonExamples `json:"examples,omitempty"`
+}
+
+type jsonExamples struct {
+	Keyword     string        `json:"keyword"`
+	Name        string        `json:"name,omitempty"`
+	Description string        `json:"description"`
+	Location    *jsonLocation `json:"location,omitempty"`
+	Tags        []jsonTag     `json:"tags"`
+	Table       [][]jsonCell  `json:"table"`
+}
+
+type jsonStep struct {
+	Keyword     string         `json:"keyword"`
+	KeywordType string         `json:"keywordType"`
+	Text        string         `json:"text"`
+	StepText    string         `json:"stepText"`
+	Data        []string       `json:"data"`
+	Location    jsonLocation   `json:"location"`
+	Table       [][]jsonCell   `json:"table,omitempty"`
+	DocString   *jsonDocString `json:"docString,omitempty"`
+}
+
+type jsonDocString struct {
+	Content   string       `json:"content"`
+	Delimiter string       `json:"delimiter,omitempty"`
+	MediaType string       `json:"mediaType,omitempty"`
+	Location  jsonLocation `json:"location"`
+}
+
+type jsonCell struct {
+	Value    string       `json:"value"`
+	Location jsonLocation `json:"location"`
+}
+
+// stepTypes are the token types of the step keywords by their keywordType
+var stepTypes = map[string]token.Type{
+	token.GIVEN.String(): token.GIVEN,
+	token.WHEN.String():  token.WHEN,
+	token.THEN.String():  token.THEN,
+	token.AND.String():   token.AND,
+	token.BUT.String():   token.BUT,
+}
+
+// MarshalJSON marshals the FeatureSet as the JSON document described by the
+// schema of SchemaVersion
+func (fs FeatureSet) MarshalJSON() ([]byte, error) {
+	doc := jsonFeatureSet{SchemaVersion: SchemaVersion, Features: []json.RawMessage{}}
+	for i := range fs.Features {
+		feature, err := json.Marshal(fs.Features[i])
+		if err != nil {
+			return nil, err
+		}
+		doc.Features = append(doc.Features, feature)
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON unmarshals the JSON document of a FeatureSet, the documents
+// of another major version of the schema are not accepted
+func (fs *FeatureSet) UnmarshalJSON(data []byte) error {
+	var doc jsonFeatureSet
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := checkSchemaVersion(doc.SchemaVersion); err != nil {
+		return err
+	}
+	features := make([]Feature, len(doc.Features))
+	for i, feature := range doc.Features {
+		if err := json.Unmarshal(feature, &features[i]); err != nil {
+			return err
+		}
+	}
+	fs.Features = features
+	return nil
+}
+
+// UnmarshalFeatureSet unmarshals the FeatureSet marshalled as JSON
+//
+// The tree is the same as the parsed one apart from the byte offsets of the
+// tokens, which are not part of the JSON and are left 0.
+func UnmarshalFeatureSet(data []byte) (*FeatureSet, error) {
+	fs := &FeatureSet{}
+	if err := json.Unmarshal(data, fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func checkSchemaVersion(version string) error {
+	major := strings.SplitN(SchemaVersion, ".", 2)[0]
+	if strings.SplitN(version, ".", 2)[0] != major {
+		return fmt.Errorf("unsupported schema version %q, expected %v.x", version, major)
+	}
+	return nil
+}
+
+// MarshalJSON marshals the Feature as the feature of the JSON document
+func (f Feature) MarshalJSON() ([]byte, error) {
+	scenarios, err := marshalScenarios(f.Scenarios)
+	if err != nil {
+		return nil, err
+	}
+	doc := jsonFeature{
+		URI:         f.URI,
+		Language:    f.Language,
+		Keyword:     f.Token.Literal,
+		Title:       f.Title,
+		Description: f.Description,
+		Location:    tokenLocation(f.Token),
+		Tags:        marshalTags(f.Tags, f.TagTokens),
+		Background:  marshalBackground(f.Background),
+		Scenarios:   scenarios,
+		Rules:       []jsonRule{},
+		Comments:    []jsonComment{},
+	}
+	for _, rule := range f.Rules {
+		scenarios, err := marshalScenarios(rule.Scenarios)
+		if err != nil {
+			return nil, err
+		}
+		doc.Rules = append(doc.Rules, jsonRule{
+			Keyword:     rule.Token.Literal,
+			Title:       rule.Title,
+			Description: rule.Description,
+			Location:    tokenLocation(rule.Token),
+			Tags:        marshalTags(rule.Tags, rule.TagTokens),
+			Background:  marshalBackground(rule.Background),
+			Scenarios:   scenarios,
+		})
+	}
+	for _, comment := range f.Comments {
+		doc.Comments = append(doc.Comments, jsonComment{Text: comment.Literal, Location: tokenLocation(comment)})
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON unmarshals the feature of the JSON document
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	var doc jsonFeature
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	scenarios, err := unmarshalScenarios(doc.Scenarios)
+	if err != nil {
+		return err
+	}
+	*f = Feature{
+		URI:         doc.URI,
+		Language:    doc.Language,
+		Title:       doc.Title,
+		Token:       locationToken(token.FEATURE, doc.Keyword, doc.Location),
+		Description: doc.Description,
+		Scenarios:   scenarios,
+		Background:  unmarshalBackground(doc.Background),
+	}
+	f.Tags, f.TagTokens = unmarshalTags(doc.Tags)
+	for _, r := range doc.Rules {
+		scenarios, err := unmarshalScenarios(r.Scenarios)
+		if err != nil {
+			return err
+		}
+		rule := Rule{
+			Title:       r.Title,
+			Token:       locationToken(token.RULE, r.Keyword, r.Location),
+			Description: r.Description,
+			Background:  unmarshalBackground(r.Background),
+			Scenarios:   scenarios,
+		}
+		rule.Tags, rule.TagTokens = unmarshalTags(r.Tags)
+		f.Rules = append(f.Rules, rule)
+	}
+	for _, comment := range doc.Comments {
+		f.Comments = append(f.Comments, locationToken(token.COMMENT, comment.Text, comment.Location))
+	}
+	return nil
+}
+
+func marshalScenarios(scenarioTypes []ScenarioType) ([]json.RawMessage, error) {
+	res := []json.RawMessage{}
+	for _, scenarioType := range scenarioTypes {
+		var doc jsonScenarioType
+		switch scenario := scenarioType.(type) {
+		case *Scenario:
+			doc = jsonScenarioType{
+				Type:        jsonScenario,
+				Keyword:     scenario.Keyword,
+				Title:       scenario.ScenarioText,
+				Description: scenario.Description,
+				Location:    jsonLocation{scenario.LineNumber, scenario.Column},
+				Tags:        marshalTags(scenario.Tags, scenario.TagTokens),
+				Steps:       marshalSteps(scenario.Steps),
+			}
+		case *ScenarioOutline:
+			doc = jsonScenarioType{
+				Type:        jsonScenarioOutline,
+				Keyword:     scenario.Keyword,
+				Title:       scenario.ScenarioText,
+				Description: scenario.Description,
+				Location:    jsonLocation{scenario.LineNumber, scenario.Column},
+				Tags:        marshalTags(scenario.Tags, scenario.TagTokens),
+				Steps:       marshalSteps(scenario.Steps),
+				Examples:    marshalExamples(scenario),
+			}
+		default:
+			return nil, fmt.Errorf("can not marshal scenario of type %T", scenarioType)
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+	return res, nil
+}
+
+func unmarshalScenarios(docs []json.RawMessage) ([]ScenarioType, error) {
+	var scenarios []ScenarioType
+	for _, data := range docs {
+		var doc jsonScenarioType
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		switch doc.Type {
+		case jsonScenario:
+			scenario := &Scenario{
+				Steps:        unmarshalSteps(doc.Steps),
+				Keyword:      doc.Keyword,
+				ScenarioText: doc.Title,
+				Description:  doc.Description,
+				LineNumber:   doc.Location.Line,
+				Column:       doc.Location.Column,
+			}
+			scenario.Tags, scenario.TagTokens = unmarshalTags(doc.Tags)
+			scenarios = append(scenarios, scenario)
+		case jsonScenarioOutline:
+			outline := &ScenarioOutline{
+				Steps:        unmarshalSteps(doc.Steps),
+				Keyword:      doc.Keyword,
+				ScenarioText: doc.Title,
+				Description:  doc.Description,
+				LineNumber:   doc.Location.Line,
+				Column:       doc.Location.Column,
+			}
+			outline.Tags, outline.TagTokens = unmarshalTags(doc.Tags)
+			unmarshalExamples(outline, doc.Examples)
+			outline.IndexPlaceholders()
+			scenarios = append(scenarios, outline)
+		default:
+			return nil, fmt.Errorf("unknown scenario type %q", doc.Type)
+		}
+	}
+	return scenarios, nil
+}
+
+// marshalExamples joins the table, the name, the tags, the description and
+// the token of every Examples block of the outline
+func marshalExamples(outline *ScenarioOutline) []jsonExamples {
+	res := []jsonExamples{}
+	for i, table := range outline.Tables {
+		examples := jsonExamples{Table: marshalTable(table), Tags: []jsonTag{}}
+		if examples.Table == nil {
+			examples.Table = [][]jsonCell{}
+		}
+		if i < len(outline.TableTokens) {
+			location := tokenLocation(outline.TableTokens[i])
+			examples.Keyword = outline.TableTokens[i].Literal
+			examples.Location = &location
+		}
+		if i < len(outline.TableNames) {
+			examples.Name = outline.TableNames[i]
+		}
+		if i < len(outline.TableDescriptions) {
+			examples.Description = outline.TableDescriptions[i]
+		}
+		if i < len(outline.TableTags) {
+			var tokens []token.Token
+			if i < len(outline.TableTagTokens) {
+				tokens = outline.TableTagTokens[i]
+			}
+			examples.Tags = marshalTags(outline.TableTags[i], tokens)
+		}
+		res = append(res, examples)
+	}
+	return res
+}
+
+func unmarshalExamples(outline *ScenarioOutline, docs []jsonExamples) {
+	for _, examples := range docs {
+		outline.Tables = append(outline.Tables, unmarshalTable(examples.Table))
+		outline.TableNames = append(outline.TableNames, examples.Name)
+		outline.TableDescriptions = append(outline.TableDescriptions, examples.Description)
+		tags, tagTokens := unmarshalTags(examples.Tags)
+		outline.TableTags = append(outline.TableTags, tags)
+		outline.TableTagTokens = append(outline.TableTagTokens, tagTokens)
+		if examples.Location != nil {
+			outline.TableTokens = append(outline.TableTokens, locationToken(token.EXAMPLES, examples.Keyword, *examples.Location))
+		}
+	}
+}
+
+func marshalBackground(background *Background) *jsonBackground {
+	if background == nil {
+		return nil
+	}
+	return &jsonBackground{
+		Keyword:  background.Keyword,
+		Title:    background.Title,
+		Location: jsonLocation{background.LineNumber, background.Column},
+		Steps:    marshalSteps(background.Steps),
+	}
+}
+
+func unmarshalBackground(doc *jsonBackground) *Background {
+	if doc == nil {
+		return nil
+	}
+	return &Background{
+		Steps:      unmarshalSteps(doc.Steps),
+		Title:      doc.Title,
+		Keyword:    doc.Keyword,
+		LineNumber: doc.Location.Line,
+		Column:     doc.Location.Column,
+	}
+}
+
+func marshalSteps(steps []Step) []jsonStep {
+	res := []jsonStep{}
+	for _, step := range steps {
+		s := jsonStep{
+			Keyword:     step.Token.Literal,
+			KeywordType: step.Token.Type.String(),
+			Text:        step.Text,
+			StepText:    step.StepText,
+			Data:        append([]string{}, step.Data...),
+			Location:    jsonLocation{step.LineNumber, step.Column},
+			Table:       marshalTable(step.Table),
+		}
+		if step.DocString != nil {
+			s.DocString = &jsonDocString{
+				Content:   step.DocString.Content,
+				Delimiter: step.DocString.Delimiter,
+				MediaType: step.DocString.MediaType,
+				Location:  jsonLocation{step.DocString.LineNumber, step.DocString.Column},
+			}
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+func unmarshalSteps(docs []jsonStep) []Step {
+	var steps []Step
+	for _, doc := range docs {
+		step := Step{
+			Token:      token.Token{Type: stepTypes[doc.KeywordType], Literal: doc.Keyword, LineNumber: doc.Location.Line, Column: doc.Location.Column},
+			Text:       doc.Text,
+			StepText:   doc.StepText,
+			Table:      unmarshalTable(doc.Table),
+			Data:       doc.Data,
+			LineNumber: doc.Location.Line,
+			Column:     doc.Location.Column,
+		}
+		if len(step.Data) == 0 {
+			step.Data = nil
+		}
+		if doc.DocString != nil {
+			step.DocString = &DocString{
+				Content:    doc.DocString.Content,
+				Delimiter:  doc.DocString.Delimiter,
+				MediaType:  doc.DocString.MediaType,
+				LineNumber: doc.DocString.Location.Line,
+				Column:     doc.DocString.Location.Column,
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func marshalTable(table Table) [][]jsonCell {
+	var rows [][]jsonCell
+	for _, row := range table {
+		cells := []jsonCell{}
+		for _, cell := range row {
+			cells = append(cells, jsonCell{Value: cell.Literal, Location: jsonLocation{cell.LineNumber, cell.Column}})
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func unmarshalTable(rows [][]jsonCell) Table {
+	var table Table
+	for _, row := range rows {
+		cells := []TableData{}
+		for _, cell := range row {
+			cells = append(cells, TableData{Literal: cell.Value, LineNumber: cell.Location.Line, Column: cell.Location.Column})
+		}
+		table = append(table, cells)
+	}
+	return table
+}
+
+// marshalTags marshals the tags with the locations of their tokens, like the
+// scenarios made by hand the tags without tokens have no location
+func marshalTags(names []string, tokens []token.Token) []jsonTag {
+	tags := []jsonTag{}
+	if len(tokens) != len(names) {
+		for _, name := range names {
+			tags = append(tags, jsonTag{Name: name})
+		}
+		return tags
+	}
+	for _, tag := range tokens {
+		location := tokenLocation(tag)
+		tags = append(tags, jsonTag{Name: tag.Literal, Location: &location})
+	}
+	return tags
+}
+
+// unmarshalTags returns the names of the tags and their tokens, the tokens
+// are only returned when every tag has a location
+func unmarshalTags(docs []jsonTag) ([]string, []token.Token) {
+	names := make([]string, 0, len(docs))
+	tokens := make([]token.Token, 0, len(docs))
+	for _, tag := range docs {
+		names = append(names, tag.Name)
+		if tag.Location != nil {
+			tokens = append(tokens, locationToken(token.TAG, tag.Name, *tag.Location))
+		}
+	}
+	if len(tokens) != len(names) {
+		tokens = nil
+	}
+	return names, tokens
+}
+
+func tokenLocation(t token.Token) jsonLocation {
+	return jsonLocation{Line: t.LineNumber, Column: t.Column}
+}
+
+func locationToken(tokenType token.Type, literal string, location jsonLocation) token.Token {
+	return token.Token{Type: tokenType, Literal: literal, LineNumber: location.Line, Column: location.Column}
+}
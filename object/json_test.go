@@ -0,0 +1,336 @@
+package object_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+	"github.com/dpakach/gorkin/token"
+)
+
+const jsonInput = `# a comment
+@feature @wip
+Feature: json
+	The description
+	of the feature
+
+	Background:
+		Given a background step
+
+	@scenario
+	Scenario: plain
+		Given I have 5 "green" apples
+		When I eat them:
+			| apple | count |
+			| green | 5     |
+		Then I write
+			"""
+			a <fruit> doc string
+			"""
+
+	Scenario Outline: eat <fruit>
+		When I eat {{<count>}} <fruit>
+
+		@first
+		Examples:
+			| fruit | count |
+			| apple | 1     |
+
+		Examples:
+			| fruit  | count |
+			| banana | 2     |
+
+	@rule
+	Rule: a rule
+		Background: rule background
+			Given a rule background step
+
+		Scenario: in the rule
+			Then it works
+`
+
+func parseFeatureSet(t *testing.T, input string) *object.FeatureSet {
+	featureSet, err := parser.ParseSource("json.feature", []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return featureSet
+}
+
+// normalize clears the offsets of the tokens, which are not marshalled, and
+// the empty slices so the trees can be compared with reflect.DeepEqual
+func normalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(token.Token{}) {
+			v.FieldByName("Offset").SetInt(0)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				normalize(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+	}
+}
+
+// reindex indexes the placeholders of the normalized outlines again
+func reindex(featureSet *object.FeatureSet) {
+	for _, feature := range featureSet.Features {
+		scenarios := feature.Scenarios
+		for _, rule := range feature.Rules {
+			scenarios = append(scenarios, rule.Scenarios...)
+		}
+		for _, scenario := range scenarios {
+			if outline, ok := scenario.(*object.ScenarioOutline); ok {
+				outline.IndexPlaceholders()
+			}
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	featureSet := parseFeatureSet(t, jsonInput)
+	data, err := json.Marshal(featureSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := object.UnmarshalFeatureSet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatalf("JSON mismatch, expected %s, got %s", data, again)
+	}
+
+	for _, fs := range []*object.FeatureSet{featureSet, result} {
+		normalize(reflect.ValueOf(fs))
+		reindex(fs)
+	}
+	if !reflect.DeepEqual(result, featureSet) {
+		t.Fatalf("FeatureSet mismatch, expected %+v, got %+v", featureSet, result)
+	}
+
+	// The outlines can be expanded right away
+	outline := result.Features[0].Scenarios[1].(*object.ScenarioOutline)
+	scenarios, err := outline.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) != 2 || scenarios[1].Steps[0].Text != "I eat {{2}} banana" {
+		t.Fatalf("Scenarios mismatch, expected the banana scenario, got %+v", scenarios)
+	}
+}
+
+func TestJSONScenarioTypes(t *testing.T) {
+	var doc struct {
+		Features []struct {
+			Scenarios []struct {
+				Type     string            `json:"type"`
+				Examples []json.RawMessage `json:"examples"`
+			} `json:"scenarios"`
+		} `json:"features"`
+	}
+	data, err := json.Marshal(parseFeatureSet(t, jsonInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := doc.Features[0].Scenarios
+	if len(scenarios) != 2 {
+		t.Fatalf("Scenarios length mismatch, expected 2, got %v", len(scenarios))
+	}
+	if scenarios[0].Type != "scenario" || scenarios[0].Examples != nil {
+		t.Fatalf("Type mismatch, expected scenario without examples, got %v", scenarios[0])
+	}
+	if scenarios[1].Type != "scenarioOutline" || len(scenarios[1].Examples) != 2 {
+		t.Fatalf("Type mismatch, expected scenarioOutline with 2 examples, got %v", scenarios[1])
+	}
+}
+
+func TestUnmarshalFeatureSetErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"schemaVersion": "2.0", "features": []}`, `unsupported schema version "2.0", expected 1.x`},
+		{`{"features": []}`, `unsupported schema version "", expected 1.x`},
+		{`{"schemaVersion": "1.0", "features": [{"scenarios": [{"type": "rule"}]}]}`, `unknown scenario type "rule"`},
+		{`{"schemaVersion": "1.0", "features": [{"title": 1}]}`, ""},
+	}
+
+	for _, tt := range tests {
+		// The messages of the json package are not compared
+		_, err := object.UnmarshalFeatureSet([]byte(tt.input))
+		if err == nil || (tt.expected != "" && err.Error() != tt.expected) {
+			t.Fatalf("Error mismatch, expected %v, got %v", tt.expected, err)
+		}
+	}
+
+	featureSet, err := object.UnmarshalFeatureSet([]byte(`{"schemaVersion": "1.3", "features": []}`))
+	if err != nil || len(featureSet.Features) != 0 {
+		t.Fatalf("Expected a minor version to be accepted but got %v, %v", featureSet, err)
+	}
+}
+
+func TestJSONHandMadeTags(t *testing.T) {
+	featureSet := &object.FeatureSet{Features: []object.Feature{{
+		Title:     "hand made",
+		Tags:      []string{"a", "b"},
+		Scenarios: []object.ScenarioType{&object.Scenario{ScenarioText: "s", Tags: []string{"c"}}},
+	}}}
+	data, err := json.Marshal(featureSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"tags":[{"name":"a"},{"name":"b"}]`) {
+		t.Fatalf("Expected the tags without tokens to have no location, got %s", data)
+	}
+
+	result, err := object.UnmarshalFeatureSet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feature := result.Features[0]
+	if !reflect.DeepEqual(feature.Tags, []string{"a", "b"}) || feature.TagTokens != nil {
+		t.Fatalf("Tags mismatch, expected [a b] without tokens, got %v, %v", feature.Tags, feature.TagTokens)
+	}
+	if tags := feature.Scenarios[0].GetTags(); !reflect.DeepEqual(tags, []string{"c"}) {
+		t.Fatalf("Tags mismatch, expected [c], got %v", tags)
+	}
+}
+
+// validate checks the value against the subset of JSON Schema used by the
+// schema file
+func validate(schema, root map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		return validate(root["definitions"].(map[string]interface{})[name].(map[string]interface{}), root, value, path)
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, s := range oneOf {
+			if validate(s.(map[string]interface{}), root, value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%v: matches %v schemas of oneOf", path, matches)
+		}
+		return nil
+	}
+	if c, ok := schema["const"]; ok && c != value {
+		return fmt.Errorf("%v: expected %v, got %v", path, c, value)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == value
+		}
+		if !found {
+			return fmt.Errorf("%v: %v is not one of %v", path, value, enum)
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(value.(string)) {
+		return fmt.Errorf("%v: %v does not match %v", path, value, pattern)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected an object, got %v", path, value)
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%v: missing %v", path, name)
+			}
+		}
+		for name, v := range object {
+			property, ok := properties[name]
+			if !ok {
+				return fmt.Errorf("%v: unexpected property %v", path, name)
+			}
+			if err := validate(property.(map[string]interface{}), root, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected an array, got %v", path, value)
+		}
+		for i, item := range array {
+			if err := validate(schema["items"].(map[string]interface{}), root, item, fmt.Sprintf("%v[%v]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%v: expected a string, got %v", path, value)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int(n)) {
+			return fmt.Errorf("%v: expected an integer, got %v", path, value)
+		}
+	}
+	return nil
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := ioutil.ReadFile("../schema/featureset.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(object.SchemaVersion, "1.") {
+		t.Fatalf("Expected the schema of version %v to be in a new file", object.SchemaVersion)
+	}
+
+	data, err = json.Marshal(parseFeatureSet(t, jsonInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(schema, schema, value, "$"); err != nil {
+		t.Fatalf("Expected the JSON to match the schema but got %v", err)
+	}
+
+	// The validation itself catches a property missing from the schema
+	value.(map[string]interface{})["extra"] = true
+	if err := validate(schema, schema, value, "$"); err == nil {
+		t.Fatalf("Expected an unexpected property to fail the validation")
+	}
+}
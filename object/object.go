@@ -3,11 +3,8 @@ package object
 import (
 	"errors"
 	"fmt"
-	"regexp"
-	"strings"
 
 	"github.com/dpakach/gorkin/token"
-	"github.com/dpakach/gorkin/utils"
 )
 
 type objectType string
@@ -37,25 +34,88 @@ func (fs *FeatureSet) Merge(newFs *FeatureSet) {
 }
 
 // Feature is the representation of each Feature
+//
+// URI is the path of the file the feature was parsed from, Language is the
+// language of its keywords and Comments are all the comment lines in the file
 type Feature struct {
-	Title      string
-	Token      token.Token
-	Scenarios  []ScenarioType
-	Tags       []string
-	Background *Background
+	URI         string
+	Language    string
+	Title       string
+	Token       token.Token
+	Description string
+	Scenarios   []ScenarioType
+	Rules       []Rule
+	Tags        []string
+	TagTokens   []token.Token
+	Background  *Background
+	Comments    []token.Token
+}
+
+// GetScenarios returns all the scenarios in the feature including the ones
+// inside rules, with the feature and rule background steps prepended
+func (f *Feature) GetScenarios() []Scenario {
+	var scenarios []Scenario
+	for _, scenario := range f.Scenarios {
+		scenarios = append(scenarios, scenario.GetScenarios()...)
+	}
+	for _, rule := range f.Rules {
+		scenarios = append(scenarios, rule.GetScenarios()...)
+	}
+	return f.Background.prependTo(scenarios)
+}
+
+// Rule is the representation of a Rule block inside a Feature
+type Rule struct {
+	Title       string
+	Token       token.Token
+	Description string
+	Tags        []string
+	TagTokens   []token.Token
+	Background  *Background
+	Scenarios   []ScenarioType
+}
+
+// GetScenarios returns all the scenarios in the rule with the rule
+// background steps prepended
+func (r *Rule) GetScenarios() []Scenario {
+	var scenarios []Scenario
+	for _, scenario := range r.Scenarios {
+		scenarios = append(scenarios, scenario.GetScenarios()...)
+	}
+	return r.Background.prependTo(scenarios)
 }
 
 // Background object represents the Background block in the Features
 type Background struct {
-	Steps []Step
+	Steps      []Step
+	Title      string
+	Keyword    string
+	LineNumber int
+	Column     int
+}
+
+func (b *Background) prependTo(scenarios []Scenario) []Scenario {
+	if b == nil || len(b.Steps) == 0 {
+		return scenarios
+	}
+	for i := range scenarios {
+		steps := make([]Step, 0, len(b.Steps)+len(scenarios[i].Steps))
+		steps = append(steps, b.Steps...)
+		scenarios[i].Steps = append(steps, scenarios[i].Steps...)
+	}
+	return scenarios
 }
 
 // Scenario is the representation of the Scenarios
 type Scenario struct {
 	Steps        []Step
 	Tags         []string
+	TagTokens    []token.Token
+	Keyword      string
 	ScenarioText string
+	Description  string
 	LineNumber   int
+	Column       int
 }
 
 func (s *Scenario) scenarioTypeObject() {}
@@ -69,13 +129,28 @@ func (s *Scenario) GetScenarios() []Scenario {
 }
 
 // ScenarioOutline is representation of a scenario outline object
+//
+// Tables, TableNames, TableTags and TableDescriptions hold the table, the
+// name after the keyword, the tags and the description of each Examples
+// block in the outline, TableTokens and TableTagTokens hold the tokens of the
+// Examples keyword and the tags
 type ScenarioOutline struct {
-	Steps        []Step
-	Tags         []string
-	ScenarioText string
-	LineNumber   int
-	Tables       []Table
-	TableTags    [][]string
+	Steps             []Step
+	Tags              []string
+	TagTokens         []token.Token
+	Keyword           string
+	ScenarioText      string
+	Description       string
+	LineNumber        int
+	Tables            []Table
+	TableNames        []string
+	TableTags         [][]string
+	TableDescriptions []string
+	TableTokens       []token.Token
+	TableTagTokens    [][]token.Token
+	Column            int
+
+	placeholders *OutlinePlaceholders
 }
 
 func (so *ScenarioOutline) scenarioTypeObject() {}
@@ -84,39 +159,210 @@ func (so *ScenarioOutline) scenarioTypeObject() {}
 func (so *ScenarioOutline) GetTags() []string { return so.Tags }
 
 // GetScenarios returns scenarios as an array
+//
+// Examples tables that can not be read as hash, eg. tables without a header
+// row, do not produce any scenarios
+//
+// The background steps are not included, the pickles package compiles the
+// fully expanded scenarios
 func (so *ScenarioOutline) GetScenarios() []Scenario {
+	scenarios, _ := so.Expand()
+	return scenarios
+}
+
+// PlaceholderError is a placeholder of a scenario outline that is not a
+// column of one of its Examples tables
+//
+// The outline does not know its file, the URI is left empty by Expand and
+// set by the callers that know the feature.
+type PlaceholderError struct {
+	URI        string
+	Name       string
+	LineNumber int
+	Column     int
+	// ExamplesLineNumber is the line of the header of the Examples table
+	ExamplesLineNumber int
+}
+
+func (e *PlaceholderError) Error() string {
+	message := fmt.Sprintf(
+		"%v:%v: placeholder <%v> is not a column of the examples on line %v",
+		e.LineNumber, e.Column, e.Name, e.ExamplesLineNumber,
+	)
+	if e.URI == "" {
+		return message
+	}
+	return e.URI + ":" + message
+}
+
+// Expand returns the scenarios of the outline like GetScenarios with every
+// placeholder in the title, the steps, their data, tables and DocStrings
+// substituted by the values of the example rows
+//
+// A PlaceholderError is returned for the first placeholder that is not a
+// column of an Examples table, the scenarios are still returned with such
+// placeholders kept as they are. An ExamplesRowError is returned for the
+// first example row with more or fewer cells than the header, such rows are
+// left out.
+func (so *ScenarioOutline) Expand() ([]Scenario, error) {
 	var scenarios []Scenario
 	var steps []Step
+	var firstErr error
+	placeholders := so.Placeholders()
 	for j, table := range so.Tables {
-		for i, row := range table.GetHash() {
-			line := table[i+1][0].LineNumber
-			steps = []Step{}
-			for _, step := range so.Steps {
-				steps = append(steps, *step.substituteExampleTable(row))
+		if len(table) == 0 || len(table[0]) == 0 {
+			continue
+		}
+		header := table[0]
+		if err := so.checkPlaceholders(placeholders, header); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		var tableTags []string
+		if j < len(so.TableTags) {
+			tableTags = so.TableTags[j]
+		}
+		for _, cells := range table[1:] {
+			if len(cells) != len(header) {
+				if firstErr == nil {
+					firstErr = examplesRowError(header, cells)
+				}
+				continue
+			}
+			row := make(map[string]string, len(header))
+			for i, key := range header {
+				row[key.Literal] = cells[i].Literal
+			}
+			line := cells[0].LineNumber
+			column := cells[0].Column
+			steps = make([]Step, 0, len(so.Steps))
+			for k := range so.Steps {
+				steps = append(steps, *so.Steps[k].substituteExampleTable(&placeholders.Steps[k], row))
 			}
-			newTags := append(so.Tags, so.TableTags[j]...)
+			newTags := append(append([]string{}, so.Tags...), tableTags...)
 			scenarios = append(
 				scenarios,
-				Scenario{steps, newTags, so.ScenarioText, line},
+				Scenario{
+					Steps:        steps,
+					Tags:         newTags,
+					Keyword:      so.Keyword,
+					ScenarioText: placeholders.Title.Substitute(row),
+					Description:  so.Description,
+					LineNumber:   line,
+					Column:       column,
+				},
 			)
 		}
 	}
-	return scenarios
+	return scenarios, firstErr
+}
+
+// ExamplesRowError is an example row with more or fewer cells than the
+// header of its Examples table
+//
+// Like for a PlaceholderError the URI is set by the callers that know the
+// feature.
+type ExamplesRowError struct {
+	URI        string
+	LineNumber int
+	Column     int
+	Cells      int
+	Columns    int
+}
+
+func (e *ExamplesRowError) Error() string {
+	message := fmt.Sprintf("%v:%v: example row has %v cells, expected %v", e.LineNumber, e.Column, e.Cells, e.Columns)
+	if e.URI == "" {
+		return message
+	}
+	return e.URI + ":" + message
+}
+
+func examplesRowError(header, row []TableData) *ExamplesRowError {
+	err := &ExamplesRowError{Cells: len(row), Columns: len(header), LineNumber: header[0].LineNumber, Column: header[0].Column}
+	if len(row) != 0 {
+		err.LineNumber, err.Column = row[0].LineNumber, row[0].Column
+	}
+	return err
+}
+
+// checkPlaceholders returns an error for the first placeholder that is not a
+// column in the header
+func (so *ScenarioOutline) checkPlaceholders(placeholders *OutlinePlaceholders, header []TableData) error {
+	columns := make(map[string]bool, len(header))
+	for _, cell := range header {
+		columns[cell.Literal] = true
+	}
+	check := func(template Template, line, column int) error {
+		for _, placeholder := range template.Placeholders {
+			if !columns[placeholder.Name] {
+				return &PlaceholderError{
+					Name:               placeholder.Name,
+					LineNumber:         line,
+					Column:             column,
+					ExamplesLineNumber: header[0].LineNumber,
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := check(placeholders.Title, so.LineNumber, so.Column); err != nil {
+		return err
+	}
+	for i, step := range so.Steps {
+		if err := check(placeholders.Steps[i].Text, step.LineNumber, step.Column); err != nil {
+			return err
+		}
+		for j, row := range placeholders.Steps[i].Table {
+			for k, cell := range row {
+				if j >= len(step.Table) || k >= len(step.Table[j]) {
+					continue
+				}
+				if err := check(cell, step.Table[j][k].LineNumber, step.Table[j][k].Column); err != nil {
+					return err
+				}
+			}
+		}
+		if step.DocString != nil {
+			if err := check(placeholders.Steps[i].DocString, step.DocString.LineNumber, step.DocString.Column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // Step is a representation of a Step in Gherkin
+//
+// Text is the step text as written after the keyword, StepText is the same
+// text with the data replaced by placeholders which are kept in Data
 type Step struct {
 	Token      token.Token
+	Text       string
 	StepText   string
 	Table      Table
+	DocString  *DocString
 	Data       []string
 	LineNumber int
+	Column     int
+}
+
+// DocString is a representation of a DocString argument of a Step, the
+// Delimiter is the """ or ``` fence it is written with and the MediaType is
+// the text after the opening fence
+type DocString struct {
+	Content    string
+	Delimiter  string
+	MediaType  string
+	LineNumber int
+	Column     int
 }
 
 // TableData is a representation of a cell in a gherkin Table
 type TableData struct {
 	Literal    string
 	LineNumber int
+	Column     int
 }
 
 // Table is a representation of any Table in Gherkin
@@ -138,92 +384,57 @@ func TableFromString(strTable [][]string, startingLine int) Table {
 	return res
 }
 
-func (s *Step) recompileText() {
-	count := 0
-	for i := 0; i < len(s.StepText); i++ {
-		digits := []byte{}
-		if s.StepText[i] >= '0' && s.StepText[i] <= '9' {
-
-			// If the text contains digits add it to s.Data
-			for s.StepText[i] >= '0' && s.StepText[i] <= '9' {
-				digits = append(digits, s.StepText[i])
-				i++
-				if i == len(s.StepText) {
-					break
-				}
-			}
-			s.StepText = utils.ReplaceNth(s.StepText, string(digits), "{{d}}", 1)
-
-			// Add new Number to the Data array
-			s.Data = append(s.Data, "")
-			copy(s.Data[count+1:], s.Data[count:])
-			s.Data[count] = string(digits)
-
-			// Track `{{` in the text to track the position in Data array
-			if s.StepText[i] == '{' && s.StepText[i+1] == '{' {
-				count++
-			}
-		}
-	}
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
 }
 
-func (s *Step) substituteExampleTable(row map[string]string) *Step {
-	var step = &Step{}
-
-	step.Token = s.Token
-	step.StepText = s.StepText
-	step.LineNumber = s.LineNumber
-
-	step.Table = make([][]TableData, len(s.Table))
-
-	step.Data = []string{}
-	for _, data := range s.Data {
-		step.Data = append(step.Data, data)
+// substituteExampleTable returns a copy of the step with the values of the
+// example row substituted in the step text, the data, the table and the
+// DocString
+func (s *Step) substituteExampleTable(placeholders *StepPlaceholders, row map[string]string) *Step {
+	step := &Step{
+		Token:      s.Token,
+		Text:       placeholders.Text.Substitute(row),
+		DocString:  s.DocString,
+		StepText:   s.StepText,
+		Table:      s.Table,
+		LineNumber: s.LineNumber,
+		Column:     s.Column,
+		Data:       make([]string, len(s.Data)),
 	}
 
-	// First substitute the {{<data>}} from the step.StepText
-	r := regexp.MustCompile("{{<[a-zA-Z0-9_]*>}}")
-	if r.MatchString(s.StepText) {
-		sup := r.FindString(s.StepText)
-		sup = sup[3 : len(sup)-3]
-		step.StepText = strings.ReplaceAll(step.StepText, fmt.Sprintf("{{<%v>}}", sup), row[sup])
-		step.recompileText()
-	}
-
-	// Then substitute <data> occurances from the step.Data
-	r = regexp.MustCompile("<[a-zA-Z0-9_]*>")
-	for i, data := range step.Data {
-		if r.MatchString(data) {
-			sup := r.FindString(data)
-			sup = sup[1 : len(sup)-1]
-			step.Data[i] = strings.ReplaceAll(data, fmt.Sprintf("<%v>", sup), row[sup])
+	for i := range step.Data {
+		if i < len(placeholders.Data) {
+			step.Data[i] = placeholders.Data[i].Substitute(row)
+		} else {
+			step.Data[i] = s.Data[i]
 		}
 	}
+	// The numbers in the values of the {{<data>}} in the StepText are moved
+	// to the Data
+	if len(placeholders.StepText.Placeholders) != 0 {
+		step.StepText, step.Data = placeholders.substituteStepText(row, step.Data)
+	}
 
-	// Then substitute <data> occurances from the step.Table
-	for i, rw := range s.Table {
-		for _, rowi := range rw {
-			if r.MatchString(rowi.Literal) {
-				sup := r.FindString(rowi.Literal)
-				sup = sup[1 : len(sup)-1]
-				step.Table[i] = append(
-					step.Table[i],
-					TableData{
-						Literal:    strings.ReplaceAll(rowi.Literal, fmt.Sprintf("<%v>", sup), row[sup]),
-						LineNumber: rowi.LineNumber,
-					},
-				)
-			} else {
-				step.Table[i] = append(
-					step.Table[i],
-					TableData{
-						Literal:    rowi.Literal,
-						LineNumber: rowi.LineNumber,
-					},
-				)
+	// The table is shared when none of the cells has a placeholder
+	if placeholders.Table != nil {
+		step.Table = make(Table, len(s.Table))
+		for i, cells := range s.Table {
+			step.Table[i] = make([]TableData, len(cells))
+			for j, cell := range cells {
+				// The cells added after indexing have no placeholders
+				if i < len(placeholders.Table) && j < len(placeholders.Table[i]) {
+					cell.Literal = placeholders.Table[i][j].Substitute(row)
+				}
+				step.Table[i][j] = cell
 			}
 		}
 	}
+	if s.DocString != nil && len(placeholders.DocString.Placeholders) != 0 {
+		docString := *s.DocString
+		docString.Content = placeholders.DocString.Substitute(row)
+		step.DocString = &docString
+	}
 	return step
 }
 
@@ -243,15 +454,24 @@ func (t *Table) GetRow(i int) ([]TableData, error) {
 }
 
 // GetHash returns the data from a table as array of Hash
-func (t *Table) GetHash() []map[string]string {
+//
+// The first row of the table is used as the keys, an error is returned when
+// the table is empty or a row does not have a cell for every key
+func (t *Table) GetHash() ([]map[string]string, error) {
 	keys, err := t.GetRow(0)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the table has an empty header row")
 	}
 
 	hash := []map[string]string{}
 
-	for _, row := range t.GetRows()[1:] {
+	for n, row := range t.GetRows()[1:] {
+		if len(row) != len(keys) {
+			return nil, fmt.Errorf("row %d of the table has %d cells, expected %d", n+2, len(row), len(keys))
+		}
 		rowMap := map[string]string{}
 		for i, key := range keys {
 			rowMap[key.Literal] = row[i].Literal
@@ -259,7 +479,7 @@ func (t *Table) GetHash() []map[string]string {
 		hash = append(hash, rowMap)
 	}
 
-	return hash
+	return hash, nil
 }
 
 // Append adds given array of TableData to table
@@ -1,8 +1,10 @@
 package object
 
 import (
-	"github.com/dpakach/gorkin/token"
+	"fmt"
 	"testing"
+
+	"github.com/dpakach/gorkin/token"
 )
 
 func areArrayEqual(a, b []string) bool {
@@ -90,7 +92,11 @@ func TestTableGetRows(t *testing.T) {
 		t.Fatalf("Expected table to be %v but got %v", expectedTable, parsed)
 	}
 
-	for i, item := range parsed.GetHash() {
+	hash, err := parsed.GetHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range hash {
 		if !areMapEqual(item, expectedHash[i]) {
 			t.Fatalf("Expected table hash to be %v but got %v", expectedHash[i], item)
 		}
@@ -156,7 +162,8 @@ func TestSubstituteTable(t *testing.T) {
 		LineNumber: 1,
 	}
 
-	res := step.substituteExampleTable(hash[0])
+	placeholders := step.placeholders()
+	res := step.substituteExampleTable(&placeholders, hash[0])
 	assertStepsEqual(t, expected0, res)
 
 	expected1 := &Step{
@@ -172,7 +179,7 @@ func TestSubstituteTable(t *testing.T) {
 		Data:       []string{"string"},
 		LineNumber: 1,
 	}
-	res = step.substituteExampleTable(hash[1])
+	res = step.substituteExampleTable(&placeholders, hash[1])
 	assertStepsEqual(t, expected1, res)
 }
 
@@ -229,28 +236,28 @@ func assertScenariosEqual(t *testing.T, expected, actual *Scenario) {
 
 var stepDataProvider = []Step{
 	{
-		token.Token{token.GIVEN, "Given", 1},
-		"some test step {{<with>}}",
-		nil,
-		nil,
-		1,
+		Token:      token.Token{Type: token.GIVEN, Literal: "Given", LineNumber: 1},
+		StepText:   "some test step {{<with>}}",
+		Table:      nil,
+		Data:       nil,
+		LineNumber: 1,
 	},
 	{
-		token.Token{token.THEN, "Then", 1},
-		"some data is {{s}}",
-		nil,
-		[]string{"5"},
-		2,
+		Token:      token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+		StepText:   "some data is {{s}}",
+		Table:      nil,
+		Data:       []string{"5"},
+		LineNumber: 2,
 	},
 	{
-		token.Token{token.THEN, "Then", 1},
-		"some {{s}} has a table",
-		TableFromString([][]string{
+		Token:    token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+		StepText: "some {{s}} has a table",
+		Table: TableFromString([][]string{
 			[]string{"<with>", "5"},
 			[]string{"and", "<data>"},
 		}, 4),
-		[]string{"<with>"},
-		3,
+		Data:       []string{"<with>"},
+		LineNumber: 3,
 	},
 }
 
@@ -267,46 +274,46 @@ var stepDataProvider = []Step{
 
 func TestGetScenarios(t *testing.T) {
 	scenarioOutline := &ScenarioOutline{
-		stepDataProvider,
-		[]string{},
-		"Test Scenario",
-		1,
-		[]Table{
+		Steps:        stepDataProvider,
+		Tags:         []string{},
+		ScenarioText: "Test Scenario",
+		LineNumber:   1,
+		Tables: []Table{
 			TableFromString([][]string{
 				[]string{"with", "data"},
 				[]string{"4", "5"},
 				[]string{"and", "string"},
 			}, 4),
 		},
-		[][]string{{}},
+		TableTags: [][]string{{}},
 	}
 
 	expectedScenarios := []Scenario{
 		Scenario{
 			Steps: []Step{
 				{
-					token.Token{token.GIVEN, "Given", 1},
-					"some test step {{d}}",
-					nil,
-					[]string{"4"},
-					1,
+					Token:      token.Token{Type: token.GIVEN, Literal: "Given", LineNumber: 1},
+					StepText:   "some test step {{d}}",
+					Table:      nil,
+					Data:       []string{"4"},
+					LineNumber: 1,
 				},
 				{
-					token.Token{token.THEN, "Then", 1},
-					"some data is {{s}}",
-					nil,
-					[]string{"5"},
-					2,
+					Token:      token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+					StepText:   "some data is {{s}}",
+					Table:      nil,
+					Data:       []string{"5"},
+					LineNumber: 2,
 				},
 				{
-					token.Token{token.THEN, "Then", 1},
-					"some {{s}} has a table",
-					TableFromString([][]string{
+					Token:    token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+					StepText: "some {{s}} has a table",
+					Table: TableFromString([][]string{
 						[]string{"4", "5"},
 						[]string{"and", "5"},
 					}, 4),
-					[]string{"4"},
-					3,
+					Data:       []string{"4"},
+					LineNumber: 3,
 				},
 			},
 			Tags:         []string{},
@@ -316,28 +323,28 @@ func TestGetScenarios(t *testing.T) {
 		Scenario{
 			Steps: []Step{
 				{
-					token.Token{token.GIVEN, "Given", 1},
-					"some test step and",
-					nil,
-					nil,
-					1,
+					Token:      token.Token{Type: token.GIVEN, Literal: "Given", LineNumber: 1},
+					StepText:   "some test step and",
+					Table:      nil,
+					Data:       nil,
+					LineNumber: 1,
 				},
 				{
-					token.Token{token.THEN, "Then", 1},
-					"some data is {{s}}",
-					nil,
-					[]string{"5"},
-					2,
+					Token:      token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+					StepText:   "some data is {{s}}",
+					Table:      nil,
+					Data:       []string{"5"},
+					LineNumber: 2,
 				},
 				{
-					token.Token{token.THEN, "Then", 1},
-					"some {{s}} has a table",
-					TableFromString([][]string{
+					Token:    token.Token{Type: token.THEN, Literal: "Then", LineNumber: 1},
+					StepText: "some {{s}} has a table",
+					Table: TableFromString([][]string{
 						[]string{"and", "5"},
 						[]string{"and", "string"},
 					}, 4),
-					[]string{"and"},
-					3,
+					Data:       []string{"and"},
+					LineNumber: 3,
 				},
 			},
 			Tags:         []string{},
@@ -351,3 +358,264 @@ func TestGetScenarios(t *testing.T) {
 	assertScenariosEqual(t, &expectedScenarios[0], &scenarios[0])
 	assertScenariosEqual(t, &expectedScenarios[1], &scenarios[1])
 }
+
+func TestFeatureGetScenarios(t *testing.T) {
+	background := func(text string) *Background {
+		return &Background{Steps: []Step{{
+			Token:    token.Token{Type: token.GIVEN, Literal: "Given", LineNumber: 1},
+			StepText: text,
+		}}}
+	}
+	scenario := func(title string) *Scenario {
+		return &Scenario{
+			Steps: []Step{{
+				Token:    token.Token{Type: token.WHEN, Literal: "When", LineNumber: 1},
+				StepText: title + " step",
+			}},
+			ScenarioText: title,
+		}
+	}
+	feature := &Feature{
+		Background: background("feature background"),
+		Scenarios:  []ScenarioType{scenario("feature scenario")},
+		Rules: []Rule{
+			{
+				Background: background("rule background"),
+				Scenarios:  []ScenarioType{scenario("rule scenario")},
+			},
+			{
+				Scenarios: []ScenarioType{scenario("plain rule scenario")},
+			},
+		},
+	}
+
+	expected := [][]string{
+		{"feature background", "feature scenario step"},
+		{"feature background", "rule background", "rule scenario step"},
+		{"feature background", "plain rule scenario step"},
+	}
+
+	scenarios := feature.GetScenarios()
+	if len(scenarios) != len(expected) {
+		t.Fatalf("Expected number of scenarios to be %v but got %v", len(expected), len(scenarios))
+	}
+	for i, steps := range expected {
+		var actual []string
+		for _, step := range scenarios[i].Steps {
+			actual = append(actual, step.StepText)
+		}
+		if !areArrayEqual(steps, actual) {
+			t.Fatalf("Steps mismatch, expected %v, got %v", steps, actual)
+		}
+	}
+
+	if len(feature.Rules[0].Scenarios[0].(*Scenario).Steps) != 1 {
+		t.Fatal("Expected GetScenarios to not modify the original scenario steps")
+	}
+}
+
+func TestTableGetHashErrors(t *testing.T) {
+	testData := []struct {
+		table         Table
+		expectedError string
+	}{
+		{Table{}, "the row you requested does not exist"},
+		{Table{{}}, "the table has an empty header row"},
+		{
+			TableFromString([][]string{{"with", "data"}, {"4", "5"}, {"and"}}, 1),
+			"row 3 of the table has 1 cells, expected 2",
+		},
+	}
+
+	for _, tt := range testData {
+		hash, err := tt.table.GetHash()
+		if err == nil {
+			t.Fatalf("Expected error %q but got hash %v", tt.expectedError, hash)
+		}
+		if err.Error() != tt.expectedError {
+			t.Fatalf("Error mismatch, expected %q, got %q", tt.expectedError, err.Error())
+		}
+	}
+
+	outline := ScenarioOutline{
+		ScenarioText: "broken examples",
+		Steps:        []Step{{StepText: "a step {{<with>}}", Data: []string{}}},
+		Tables: []Table{
+			{},
+			TableFromString([][]string{{"with"}, {"4"}}, 1),
+		},
+	}
+	scenarios := outline.GetScenarios()
+	if len(scenarios) != 1 {
+		t.Fatalf("Scenario count mismatch, expected %v, got %v", 1, len(scenarios))
+	}
+	if scenarios[0].Steps[0].StepText != "a step {{d}}" {
+		t.Fatalf("Step text mismatch, expected %q, got %q", "a step {{d}}", scenarios[0].Steps[0].StepText)
+	}
+}
+
+// Scenario Outline: <user> shopping
+//   When <user> buys <qty> of <item> for "<user> <item>"
+//     | <item> x<qty> | <qty> |
+//     """
+//     <user> paid for <qty>
+//     """
+//
+// Examples:
+//   | user  | qty | item   |
+//   | alice | 2   | apples |
+
+func TestExpandMultiplePlaceholders(t *testing.T) {
+	outline := &ScenarioOutline{
+		ScenarioText: "<user> shopping",
+		LineNumber:   1,
+		Steps: []Step{
+			{
+				Token:      token.Token{Type: token.WHEN, Literal: "When", LineNumber: 2},
+				Text:       `<user> buys <qty> of <item> for "<user> <item>"`,
+				StepText:   "{{<user>}} buys {{<qty>}} of {{<item>}} for {{s}}\n{{s}}",
+				Table:      TableFromString([][]string{{"<item> x<qty>", "<qty>"}}, 3),
+				DocString:  &DocString{Content: "<user> paid for <qty>", LineNumber: 4},
+				Data:       []string{"<user> <item>", "<user> paid for <qty>"},
+				LineNumber: 2,
+			},
+		},
+		Tables: []Table{TableFromString([][]string{{"user", "qty", "item"}, {"alice", "2", "apples"}}, 9)},
+	}
+	outline.IndexPlaceholders()
+
+	scenarios, err := outline.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) != 1 {
+		t.Fatalf("Scenario count mismatch, expected %v, got %v", 1, len(scenarios))
+	}
+	if scenarios[0].ScenarioText != "alice shopping" {
+		t.Fatalf("Scenario text mismatch, expected %q, got %q", "alice shopping", scenarios[0].ScenarioText)
+	}
+	expected := &Step{
+		Token:      token.Token{Type: token.WHEN, Literal: "When", LineNumber: 2},
+		StepText:   "alice buys {{d}} of apples for {{s}}\n{{s}}",
+		Table:      TableFromString([][]string{{"apples x2", "2"}}, 3),
+		Data:       []string{"2", "alice apples", "alice paid for 2"},
+		LineNumber: 2,
+	}
+	step := &scenarios[0].Steps[0]
+	assertStepsEqual(t, expected, step)
+	if step.Text != `alice buys 2 of apples for "alice apples"` {
+		t.Fatalf("Text mismatch, expected %q, got %q", `alice buys 2 of apples for "alice apples"`, step.Text)
+	}
+	if step.DocString.Content != "alice paid for 2" {
+		t.Fatalf("DocString mismatch, expected %q, got %q", "alice paid for 2", step.DocString.Content)
+	}
+	if outline.Steps[0].DocString.Content != "<user> paid for <qty>" {
+		t.Fatalf("Expected the outline DocString to be unchanged but got %q", outline.Steps[0].DocString.Content)
+	}
+}
+
+func TestExpandMissingColumn(t *testing.T) {
+	step := Step{
+		Token:      token.Token{Type: token.GIVEN, Literal: "Given", LineNumber: 2},
+		Text:       "<user> buys <qty>",
+		StepText:   "{{<user>}} buys {{<qty>}}",
+		LineNumber: 2,
+		Column:     5,
+	}
+	testData := []struct {
+		outline       *ScenarioOutline
+		expectedError string
+	}{
+		{
+			&ScenarioOutline{ScenarioText: "<user>", Steps: []Step{step}, Tables: []Table{
+				TableFromString([][]string{{"user", "qty"}, {"alice", "2"}}, 5),
+			}},
+			"",
+		},
+		{
+			&ScenarioOutline{ScenarioText: "<user>", Steps: []Step{step}, Tables: []Table{
+				TableFromString([][]string{{"user", "qty"}, {"alice", "2"}}, 5),
+				TableFromString([][]string{{"user"}, {"bob"}}, 8),
+			}},
+			"2:5: placeholder <qty> is not a column of the examples on line 8",
+		},
+		{
+			&ScenarioOutline{ScenarioText: "<name>", LineNumber: 1, Column: 3, Steps: []Step{step}, Tables: []Table{
+				TableFromString([][]string{{"user", "qty"}, {"alice", "2"}}, 5),
+			}},
+			"1:3: placeholder <name> is not a column of the examples on line 5",
+		},
+	}
+
+	for _, tt := range testData {
+		scenarios, err := tt.outline.Expand()
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != tt.expectedError {
+			t.Fatalf("Error mismatch, expected %q, got %q", tt.expectedError, actual)
+		}
+		if len(scenarios) != len(tt.outline.Tables) {
+			t.Fatalf("Scenario count mismatch, expected %v, got %v", len(tt.outline.Tables), len(scenarios))
+		}
+	}
+
+	// The URI is written before the location when it is known
+	_, err := testData[1].outline.Expand()
+	placeholderErr, ok := err.(*PlaceholderError)
+	if !ok {
+		t.Fatalf("Expected a *PlaceholderError but got %v", err)
+	}
+	placeholderErr.URI = "shop.feature"
+	if expected := "shop.feature:" + testData[1].expectedError; placeholderErr.Error() != expected {
+		t.Fatalf("Error mismatch, expected %q, got %q", expected, placeholderErr.Error())
+	}
+
+	// The missing placeholders are kept as they are
+	scenarios := testData[1].outline.GetScenarios()
+	if text := scenarios[1].Steps[0].Text; text != "bob buys <qty>" {
+		t.Fatalf("Text mismatch, expected %q, got %q", "bob buys <qty>", text)
+	}
+}
+
+func TestExpandExamplesRowCells(t *testing.T) {
+	outline := &ScenarioOutline{
+		ScenarioText: "buying <qty>",
+		Steps:        []Step{{Text: "I buy <qty>", StepText: "I buy {{<qty>}}"}},
+		Tables:       []Table{TableFromString([][]string{{"qty", "item"}, {"1", "apple"}, {"2"}, {"3", "pear"}}, 6)},
+	}
+
+	scenarios, err := outline.Expand()
+	rowErr, ok := err.(*ExamplesRowError)
+	if !ok {
+		t.Fatalf("Expected an *ExamplesRowError but got %v", err)
+	}
+	rowErr.URI = "shop.feature"
+	if expected := "shop.feature:8:0: example row has 1 cells, expected 2"; rowErr.Error() != expected {
+		t.Fatalf("Error mismatch, expected %q, got %q", expected, rowErr.Error())
+	}
+
+	// The other rows are still expanded
+	if len(scenarios) != 2 || scenarios[0].ScenarioText != "buying 1" || scenarios[1].ScenarioText != "buying 3" {
+		t.Fatalf("Scenarios mismatch, got %+v", scenarios)
+	}
+}
+
+func BenchmarkGetScenarios(b *testing.B) {
+	rows := [][]string{{"with", "data"}}
+	for i := 0; i < 100; i++ {
+		rows = append(rows, []string{fmt.Sprintf("value %d", i), "string"})
+	}
+	scenarioOutline := &ScenarioOutline{
+		Steps:        stepDataProvider,
+		ScenarioText: "Test Scenario",
+		LineNumber:   1,
+		Tables:       []Table{TableFromString(rows, 4)},
+		TableTags:    [][]string{{}},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		scenarioOutline.GetScenarios()
+	}
+}
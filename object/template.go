@@ -0,0 +1,218 @@
+package object
+
+import "strings"
+
+// Template is a text with the positions of its <name> placeholders indexed,
+// so the values of every example row are substituted in a single pass
+type Template struct {
+	Text         string
+	Placeholders []Placeholder
+}
+
+// Placeholder is a <name> placeholder in a Template, Start and End are the
+// byte offsets of the whole placeholder in the text
+type Placeholder struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// ParseTemplate indexes the <name> placeholders in the text, a name can have
+// any character but < and >
+func ParseTemplate(text string) Template {
+	t := Template{Text: text}
+	start := -1
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			start = i
+		case '>':
+			if start >= 0 && i > start+1 {
+				t.Placeholders = append(t.Placeholders, Placeholder{Name: text[start+1 : i], Start: start, End: i + 1})
+			}
+			start = -1
+		}
+	}
+	return t
+}
+
+// Substitute returns the text with the placeholders replaced by their values,
+// the placeholders without a value are kept as they are
+func (t Template) Substitute(values map[string]string) string {
+	if len(t.Placeholders) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	b.Grow(len(t.Text))
+	last := 0
+	for _, placeholder := range t.Placeholders {
+		value, ok := values[placeholder.Name]
+		if !ok {
+			continue
+		}
+		b.WriteString(t.Text[last:placeholder.Start])
+		b.WriteString(value)
+		last = placeholder.End
+	}
+	b.WriteString(t.Text[last:])
+	return b.String()
+}
+
+// OutlinePlaceholders are the placeholders in the title and the steps of a
+// scenario outline
+type OutlinePlaceholders struct {
+	Title Template
+	Steps []StepPlaceholders
+}
+
+// StepPlaceholders are the placeholders in a step of a scenario outline,
+// StepText has the {{<name>}} placeholders of Step.StepText and Table is nil
+// when none of the cells has a placeholder
+type StepPlaceholders struct {
+	Text      Template
+	StepText  Template
+	Data      []Template
+	Table     [][]Template
+	DocString Template
+
+	// dataBefore is the number of the {{d}} and {{s}} of the parser before
+	// every placeholder of the StepText, the ones written in the step text
+	// itself have no Data
+	dataBefore []int
+}
+
+func newOutlinePlaceholders(so *ScenarioOutline) *OutlinePlaceholders {
+	placeholders := &OutlinePlaceholders{
+		Title: ParseTemplate(so.ScenarioText),
+		Steps: make([]StepPlaceholders, len(so.Steps)),
+	}
+	for i := range so.Steps {
+		placeholders.Steps[i] = so.Steps[i].placeholders()
+	}
+	return placeholders
+}
+
+func (s *Step) placeholders() StepPlaceholders {
+	p := StepPlaceholders{
+		Text:     ParseTemplate(s.Text),
+		StepText: ParseTemplate(s.StepText),
+	}
+	// The placeholders of the StepText are written as {{<name>}}
+	for i, placeholder := range p.StepText.Placeholders {
+		if strings.HasSuffix(s.StepText[:placeholder.Start], "{{") && strings.HasPrefix(s.StepText[placeholder.End:], "}}") {
+			p.StepText.Placeholders[i].Start -= 2
+			p.StepText.Placeholders[i].End += 2
+		}
+	}
+	markers := dataMarkers(s.StepText, len(s.Data))
+	p.dataBefore = make([]int, len(p.StepText.Placeholders))
+	for i, placeholder := range p.StepText.Placeholders {
+		for _, marker := range markers {
+			if marker < placeholder.Start {
+				p.dataBefore[i]++
+			}
+		}
+	}
+	for _, data := range s.Data {
+		p.Data = append(p.Data, ParseTemplate(data))
+	}
+	found := false
+	for _, row := range s.Table {
+		cells := make([]Template, len(row))
+		for j, cell := range row {
+			cells[j] = ParseTemplate(cell.Literal)
+			found = found || len(cells[j].Placeholders) != 0
+		}
+		p.Table = append(p.Table, cells)
+	}
+	if !found {
+		p.Table = nil
+	}
+	if s.DocString != nil {
+		p.DocString = ParseTemplate(s.DocString.Content)
+	}
+	return p
+}
+
+// dataMarkers returns the offsets of the first n {{d}} and {{s}} in the step
+// text written by the parser, those are separated from the rest of the text
+// by whitespace
+func dataMarkers(stepText string, n int) []int {
+	var markers []int
+	for i := 0; i+5 <= len(stepText) && len(markers) < n; i++ {
+		if marker := stepText[i : i+5]; marker != "{{d}}" && marker != "{{s}}" {
+			continue
+		}
+		if (i == 0 || isSpace(stepText[i-1])) && (i+5 == len(stepText) || isSpace(stepText[i+5])) {
+			markers = append(markers, i)
+		}
+	}
+	return markers
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\n'
+}
+
+// substituteStepText returns the StepText and the Data of the step with the
+// values of the row substituted, the numbers in the values are moved to the
+// Data like the parser does for the numbers in a step
+func (p *StepPlaceholders) substituteStepText(row map[string]string, data []string) (string, []string) {
+	text := p.StepText.Text
+	var b strings.Builder
+	b.Grow(len(text))
+	res := make([]string, 0, len(data))
+	last, used := 0, 0
+	for i, placeholder := range p.StepText.Placeholders {
+		value, ok := row[placeholder.Name]
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:placeholder.Start])
+		before := p.dataBefore[i]
+		if before > len(data) {
+			before = len(data)
+		}
+		res = append(res, data[used:before]...)
+		used = before
+		for j := 0; j < len(value); {
+			if !isDigit(value[j]) {
+				b.WriteByte(value[j])
+				j++
+				continue
+			}
+			end := j + 1
+			for end < len(value) && isDigit(value[end]) {
+				end++
+			}
+			b.WriteString("{{d}}")
+			res = append(res, value[j:end])
+			j = end
+		}
+		last = placeholder.End
+	}
+	b.WriteString(text[last:])
+	return b.String(), append(res, data[used:]...)
+}
+
+// IndexPlaceholders indexes the placeholders of the scenario outline once so
+// they are not looked up again for every example row, the parser indexes the
+// outlines it parses
+//
+// The index is not checked against the outline, IndexPlaceholders has to be
+// called again after changing the title or the steps. Until then Expand
+// substitutes the texts as they were indexed, the cells of a table changed
+// since are kept as they are.
+func (so *ScenarioOutline) IndexPlaceholders() {
+	so.placeholders = newOutlinePlaceholders(so)
+}
+
+// Placeholders returns the placeholders in the title and the steps of the
+// scenario outline, they are indexed when IndexPlaceholders was not called or
+// the number of steps changed since
+func (so *ScenarioOutline) Placeholders() *OutlinePlaceholders {
+	if so.placeholders != nil && len(so.placeholders.Steps) == len(so.Steps) {
+		return so.placeholders
+	}
+	return newOutlinePlaceholders(so)
+}
@@ -0,0 +1,155 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/dpakach/gorkin/token"
+)
+
+func TestParseTemplate(t *testing.T) {
+	testData := []struct {
+		input string
+		names []string
+	}{
+		{"no placeholders", []string{}},
+		{"<a>", []string{"a"}},
+		{"I eat <count> <food> and <count>", []string{"count", "food", "count"}},
+		{"<with space> and <日本>", []string{"with space", "日本"}},
+		{"<> <a<b> a>b <c", []string{"b"}},
+	}
+
+	for _, tt := range testData {
+		template := ParseTemplate(tt.input)
+		var names []string
+		for _, placeholder := range template.Placeholders {
+			names = append(names, placeholder.Name)
+			if expected := "<" + placeholder.Name + ">"; tt.input[placeholder.Start:placeholder.End] != expected {
+				t.Fatalf("Placeholder mismatch for %q, expected %v, got %v", tt.input, expected, tt.input[placeholder.Start:placeholder.End])
+			}
+		}
+		if !areArrayEqual(names, tt.names) {
+			t.Fatalf("Names mismatch for %q, expected %v, got %v", tt.input, tt.names, names)
+		}
+	}
+}
+
+func TestTemplateSubstitute(t *testing.T) {
+	values := map[string]string{"count": "2", "food": "apples", "empty": ""}
+	testData := []struct {
+		input    string
+		expected string
+	}{
+		{"no placeholders", "no placeholders"},
+		{"I eat <count> <food>", "I eat 2 apples"},
+		{"<food><food>", "applesapples"},
+		{"<missing> <count><empty>", "<missing> 2"},
+	}
+
+	for _, tt := range testData {
+		template := ParseTemplate(tt.input)
+		if actual := template.Substitute(values); actual != tt.expected {
+			t.Fatalf("Substitute mismatch for %q, expected %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestIndexPlaceholders(t *testing.T) {
+	outline := &ScenarioOutline{
+		ScenarioText: "eating <food>",
+		Steps: []Step{
+			{
+				Token:     token.Token{Type: token.GIVEN, Literal: "Given"},
+				Text:      "I eat <count> <food>",
+				StepText:  "I eat {{<count>}} {{<food>}}",
+				Table:     TableFromString([][]string{{"<food>", "plain"}}, 2),
+				DocString: &DocString{Content: "about <food>"},
+				Data:      []string{"about <food>"},
+			},
+			{Token: token.Token{Type: token.THEN, Literal: "Then"}, Text: "done", StepText: "done", Table: TableFromString([][]string{{"a"}}, 4)},
+		},
+	}
+	outline.IndexPlaceholders()
+	placeholders := outline.Placeholders()
+	if placeholders != outline.placeholders {
+		t.Fatalf("Expected the indexed placeholders to be returned")
+	}
+
+	step := placeholders.Steps[0]
+	if step.StepText.Placeholders[1].Start != 18 || step.StepText.Placeholders[1].End != 28 {
+		t.Fatalf("StepText placeholder mismatch, expected 18-28, got %+v", step.StepText.Placeholders[1])
+	}
+	values := map[string]string{"count": "2", "food": "apples"}
+	expected := []string{"eating apples", "I eat 2 apples", "apples", "plain", "about apples", "about apples"}
+	actual := []string{
+		placeholders.Title.Substitute(values),
+		step.Text.Substitute(values),
+		step.Table[0][0].Substitute(values),
+		step.Table[0][1].Substitute(values),
+		step.DocString.Substitute(values),
+		step.Data[0].Substitute(values),
+	}
+	if !areArrayEqual(actual, expected) {
+		t.Fatalf("Substituted mismatch, expected %v, got %v", expected, actual)
+	}
+	if placeholders.Steps[1].Table != nil {
+		t.Fatalf("Expected no table placeholders but got %v", placeholders.Steps[1].Table)
+	}
+
+	// Changing the steps without indexing them again indexes them on the fly
+	outline.Steps = outline.Steps[:1]
+	if len(outline.Placeholders().Steps) != 1 {
+		t.Fatalf("Steps length mismatch, expected 1, got %v", len(outline.Placeholders().Steps))
+	}
+}
+
+func TestPlaceholdersChangedOutline(t *testing.T) {
+	outline := &ScenarioOutline{
+		ScenarioText: "eating <food>",
+		Steps: []Step{
+			{
+				Token:    token.Token{Type: token.GIVEN, Literal: "Given"},
+				Text:     "I eat",
+				StepText: "I eat",
+				Table:    TableFromString([][]string{{"<food>"}}, 2),
+			},
+			{Token: token.Token{Type: token.THEN, Literal: "Then"}, Text: "done", StepText: "done", Table: TableFromString([][]string{{"a"}}, 3)},
+		},
+		Tables: []Table{TableFromString([][]string{{"food"}, {"apples"}}, 5)},
+	}
+	outline.IndexPlaceholders()
+
+	// A table changed without indexing again does not break the expansion,
+	// the cells missing from the index are kept as they are
+	outline.ScenarioText = "eating <food> again"
+	outline.Steps[0].Text = "I eat <food>"
+	outline.Steps[0].Table = TableFromString([][]string{{"<food>", "<food>"}, {"b", "<food>"}}, 2)
+	outline.Steps[0].Data = []string{"<food>"}
+	outline.Steps[1].Table[0][0].Literal = "<food>"
+	scenarios, err := outline.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cells := scenarios[0].Steps[0].Table; cells[0][0].Literal != "apples" || cells[1][1].Literal != "<food>" {
+		t.Fatalf("Table mismatch, got %v", cells)
+	}
+
+	outline.IndexPlaceholders()
+	scenarios, err = outline.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario := scenarios[0]
+	expected := []string{"eating apples again", "I eat apples", "apples", "b", "apples", "apples", "apples"}
+	actual := []string{
+		scenario.ScenarioText,
+		scenario.Steps[0].Text,
+		scenario.Steps[0].Table[0][1].Literal,
+		scenario.Steps[0].Table[1][0].Literal,
+		scenario.Steps[0].Table[1][1].Literal,
+		scenario.Steps[0].Data[0],
+		scenario.Steps[1].Table[0][0].Literal,
+	}
+	if !areArrayEqual(actual, expected) {
+		t.Fatalf("Expanded mismatch, expected %v, got %v", expected, actual)
+	}
+}
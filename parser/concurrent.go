@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"context"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dpakach/gorkin/object"
+)
+
+// Source is a feature file to parse, the file in Path is parsed when Content
+// is nil
+type Source struct {
+	Path    string
+	Content []byte
+}
+
+// Timings are the durations of the phases of parsing multiple files
+//
+// Read and Parse are summed over all the files, they can be more than Total
+// when the files are parsed concurrently
+type Timings struct {
+	Walk  time.Duration
+	Read  time.Duration
+	Parse time.Duration
+	Merge time.Duration
+	Total time.Duration
+}
+
+func (t *Timings) add(other Timings) {
+	t.Walk += other.Walk
+	t.Read += other.Read
+	t.Parse += other.Parse
+	t.Merge += other.Merge
+	t.Total += other.Total
+}
+
+type parseResult struct {
+	featureSet *object.FeatureSet
+	err        *FileError
+}
+
+// ParseSources parses the sources concurrently into a single FeatureSet with
+// the features sorted by their path
+//
+// The number of files parsed at the same time is limited by opts.Workers.
+// The files of the sources without a content are parsed as they are read,
+// unless opts.KeepContent is set. Like ParseFiles the files that fail to parse are left out and
+// their errors are returned as FileErrors, sorted by the path too. When the
+// context is cancelled the parsing stops and the context error is returned.
+func ParseSources(ctx context.Context, sources []Source, opts *ParseOptions) (*object.FeatureSet, error) {
+	start := time.Now()
+	workers := opts.workers()
+	if workers > len(sources) {
+		workers = len(sources)
+	}
+
+	// Every worker writes its own timings and the results of its own
+	// sources, nothing is shared between them
+	results := make([]parseResult, len(sources))
+	timings := make([]Timings, workers)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(timings *Timings) {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = parseSourceFile(&sources[i], opts.keepContent(), timings)
+			}
+		}(&timings[w])
+	}
+
+send:
+	for i := range sources {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mergeStart := time.Now()
+	order := make([]int, len(sources))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sources[order[i]].Path < sources[order[j]].Path
+	})
+	featureSet := &object.FeatureSet{}
+	var errs FileErrors
+	for _, i := range order {
+		if results[i].err != nil {
+			errs = append(errs, results[i].err)
+			continue
+		}
+		featureSet.Merge(results[i].featureSet)
+	}
+
+	if opts != nil && opts.Timings != nil {
+		for _, t := range timings {
+			opts.Timings.add(t)
+		}
+		opts.Timings.Merge += time.Since(mergeStart)
+		opts.Timings.Total += time.Since(start)
+	}
+	if len(errs) != 0 {
+		return featureSet, errs
+	}
+	return featureSet, nil
+}
+
+func parseSourceFile(source *Source, keepContent bool, timings *Timings) parseResult {
+	if source.Content == nil && !keepContent {
+		start := time.Now()
+		read := timings.Read
+		featureSet, err := parseFile(source.Path, &timings.Read)
+		timings.Parse += time.Since(start) - (timings.Read - read)
+		return parseResult{featureSet: featureSet, err: err}
+	}
+	if source.Content == nil {
+		readStart := time.Now()
+		content, err := ioutil.ReadFile(source.Path)
+		timings.Read += time.Since(readStart)
+		if err != nil {
+			return parseResult{err: &FileError{Path: source.Path, Err: err}}
+		}
+		source.Content = content
+	}
+
+	parseStart := time.Now()
+	featureSet, err := parseSource(source.Path, source.Content)
+	timings.Parse += time.Since(parseStart)
+	return parseResult{featureSet: featureSet, err: err}
+}
+
+func (opts *ParseOptions) keepContent() bool {
+	return opts != nil && opts.KeepContent
+}
+
+func (opts *ParseOptions) workers() int {
+	if opts == nil || opts.Workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return opts.Workers
+}
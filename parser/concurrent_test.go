@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSources(t *testing.T) {
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+
+	sources := []Source{
+		{Path: filepath.Join(dir, "nested/deeper/c.feature")},
+		{Path: "inline.feature", Content: []byte("Feature: inline\n\tScenario: four\n\t\tGiven a step\n")},
+		{Path: filepath.Join(dir, "nested/broken.feature")},
+		{Path: filepath.Join(dir, "a.feature")},
+		{Path: filepath.Join(dir, "missing.feature")},
+	}
+	var timings Timings
+	featureSet, err := ParseSources(context.Background(), sources, &ParseOptions{Workers: 3, Timings: &timings})
+
+	errs, ok := err.(FileErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Expected 2 file errors but got %v", err)
+	}
+	if errs[0].Path != filepath.Join(dir, "missing.feature") || errs[1].Path != filepath.Join(dir, "nested/broken.feature") {
+		t.Fatalf("Expected the errors sorted by path but got %v, %v", errs[0].Path, errs[1].Path)
+	}
+
+	expected := []string{"first", "third", "inline"}
+	if len(featureSet.Features) != len(expected) {
+		t.Fatalf("Featureset length mismatch, expected %v, got %v", len(expected), len(featureSet.Features))
+	}
+	for i, title := range expected {
+		if featureSet.Features[i].Title != title {
+			t.Fatalf("Title mismatch, expected %v, got %v", title, featureSet.Features[i].Title)
+		}
+	}
+
+	if sources[0].Content != nil {
+		t.Fatalf("Expected %v to be parsed as it is read but got the content %q", sources[0].Path, sources[0].Content)
+	}
+	if timings.Total <= 0 || timings.Total < timings.Merge {
+		t.Fatalf("Expected the timings to be filled but got %+v", timings)
+	}
+}
+
+func TestParseSourcesKeepContent(t *testing.T) {
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+
+	sources := []Source{{Path: filepath.Join(dir, "nested/deeper/c.feature")}, {Path: filepath.Join(dir, "a.feature")}}
+	featureSet, err := ParseSources(context.Background(), sources, &ParseOptions{KeepContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(featureSet.Features) != 2 {
+		t.Fatalf("Featureset length mismatch, expected %v, got %v", 2, len(featureSet.Features))
+	}
+	if string(sources[0].Content) != featureFiles["nested/deeper/c.feature"] {
+		t.Fatalf("Expected the content of %v to be stored in the source", sources[0].Path)
+	}
+}
+
+func TestParseSourcesDeterministic(t *testing.T) {
+	var sources []Source
+	for i := 0; i < 200; i++ {
+		content := fmt.Sprintf("Feature: feature %03d\n\tScenario: scenario\n\t\tGiven step %d\n", i, i)
+		sources = append(sources, Source{Path: fmt.Sprintf("%03d.feature", 199-i), Content: []byte(content)})
+	}
+
+	for _, workers := range []int{1, 4, 16} {
+		featureSet, err := ParseSources(context.Background(), sources, &ParseOptions{Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(featureSet.Features) != len(sources) {
+			t.Fatalf("Featureset length mismatch, expected %v, got %v", len(sources), len(featureSet.Features))
+		}
+		for i, feature := range featureSet.Features {
+			expected := fmt.Sprintf("%03d.feature", i)
+			if feature.URI != expected {
+				t.Fatalf("URI mismatch with %v workers, expected %v, got %v", workers, expected, feature.URI)
+			}
+		}
+	}
+}
+
+func TestParseSourcesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sources := []Source{{Path: "a.feature", Content: []byte("Feature: a\n\tScenario: a\n\t\tGiven a\n")}}
+	featureSet, err := ParseSources(ctx, sources, nil)
+	if err != context.Canceled {
+		t.Fatalf("Expected %v but got %v", context.Canceled, err)
+	}
+	if featureSet != nil {
+		t.Fatalf("Expected no FeatureSet but got %v", featureSet)
+	}
+
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+	if _, err := ParseDirContext(ctx, dir, nil); err != context.Canceled {
+		t.Fatalf("Expected %v but got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,238 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+)
+
+// ParseOptions are the options used while parsing multiple feature files
+type ParseOptions struct {
+	// Extensions are the extensions of the files treated as feature files,
+	// defaults to ".feature"
+	Extensions []string
+	// Workers is the number of files parsed at the same time, defaults to
+	// GOMAXPROCS
+	Workers int
+	// Timings, when set, is filled with the time spent in every phase
+	Timings *Timings
+	// KeepContent reads the files of the sources without a content into
+	// memory and stores it in the source, instead of parsing them as they
+	// are read
+	KeepContent bool
+}
+
+func (opts *ParseOptions) extensions() []string {
+	if opts == nil || len(opts.Extensions) == 0 {
+		return []string{".feature"}
+	}
+	return opts.Extensions
+}
+
+// FileError is the error for a feature file that could not be read or parsed
+type FileError struct {
+	Path          string
+	Err           error
+	ParsingErrors []ParsingError
+}
+
+// Error returns the errors one per line in the "file:line:column: message"
+// format of the compilers
+func (e *FileError) Error() string {
+	return strings.Join(e.Messages(), "\n")
+}
+
+// Messages returns the message of every error in the file in the
+// "file:line:column: message" format
+func (e *FileError) Messages() []string {
+	if e.Err != nil {
+		return []string{e.Path + ": " + e.Err.Error()}
+	}
+	var messages []string
+	for _, err := range e.ParsingErrors {
+		line, column := err.Position()
+		messages = append(messages, fmt.Sprintf("%v:%v:%v: %v", e.Path, line, column, err.Reason()))
+	}
+	return messages
+}
+
+// FileErrors is the collection of errors from multiple feature files
+type FileErrors []*FileError
+
+func (e FileErrors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ParseFile parses the feature file in given path
+//
+// When the file has syntax errors the partially parsed FeatureSet is
+// returned along with the error, the error is a *FileError that errors.As
+// can get
+func ParseFile(path string) (*object.FeatureSet, error) {
+	featureSet, fileErr := parseFile(path, nil)
+	if fileErr != nil {
+		return featureSet, fileErr
+	}
+	return featureSet, nil
+}
+
+// parseFile parses the feature file as it is read, the time spent reading
+// it is added to read when it is not nil
+func parseFile(path string, read *time.Duration) (*object.FeatureSet, *FileError) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if read != nil {
+		r = &timedReader{r: file, read: read}
+	}
+	return parseReader(path, r)
+}
+
+// ParseReader parses the feature read from r, the input is parsed as it is
+// read without holding all of it in memory. The path is used like in
+// ParseSource and the error is a *FileError as well.
+func ParseReader(path string, r io.Reader) (*object.FeatureSet, error) {
+	featureSet, fileErr := parseReader(path, r)
+	if fileErr != nil {
+		return featureSet, fileErr
+	}
+	return featureSet, nil
+}
+
+func parseReader(path string, r io.Reader) (*object.FeatureSet, *FileError) {
+	l, err := lexer.NewFromReader(r, path)
+	if err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	featureSet, fileErr := parse(path, l)
+	// The lexer stops at a read error so the parsed features are incomplete
+	if err := l.Err(); err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	return featureSet, fileErr
+}
+
+// timedReader adds the time spent in the reads of r to read
+type timedReader struct {
+	r    io.Reader
+	read *time.Duration
+}
+
+func (t *timedReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.r.Read(p)
+	*t.read += time.Since(start)
+	return n, err
+}
+
+// ParseSource parses the feature source, path is used as the URI of the
+// feature and in the error messages, the error is a *FileError like the one
+// of ParseFile
+func ParseSource(path string, src []byte) (*object.FeatureSet, error) {
+	featureSet, fileErr := parseSource(path, src)
+	if fileErr != nil {
+		return featureSet, fileErr
+	}
+	return featureSet, nil
+}
+
+func parseSource(path string, src []byte) (*object.FeatureSet, *FileError) {
+	l := lexer.New(string(src))
+	l.FilePath = path
+	return parse(path, l)
+}
+
+func parse(path string, l *lexer.Lexer) (*object.FeatureSet, *FileError) {
+	p := New(l)
+	featureSet := p.Parse()
+	if len(p.Errors()) != 0 {
+		return featureSet, &FileError{Path: path, ParsingErrors: p.Errors()}
+	}
+	if featureSet == nil {
+		return nil, &FileError{Path: path, Err: errors.New("invalid feature file")}
+	}
+	return featureSet, nil
+}
+
+// ParseFiles parses the given feature files into a single FeatureSet
+//
+// A file that fails to parse does not stop the other files from being parsed,
+// the errors from every failed file are returned together as FileErrors and
+// the features from the failed files are left out of the FeatureSet. The
+// files are parsed concurrently and the features are sorted by their path.
+func ParseFiles(paths ...string) (*object.FeatureSet, error) {
+	sources := make([]Source, len(paths))
+	for i, path := range paths {
+		sources[i].Path = path
+	}
+	return ParseSources(context.Background(), sources, nil)
+}
+
+// ParseDir parses all the feature files inside the root directory and its
+// sub directories into a single FeatureSet
+func ParseDir(root string, opts *ParseOptions) (*object.FeatureSet, error) {
+	return ParseDirContext(context.Background(), root, opts)
+}
+
+// ParseDirContext is ParseDir stopping when the context is cancelled
+func ParseDirContext(ctx context.Context, root string, opts *ParseOptions) (*object.FeatureSet, error) {
+	walkStart := time.Now()
+	var sources []Source
+	var errs FileErrors
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			errs = append(errs, &FileError{Path: path, Err: err})
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+		for _, ext := range opts.extensions() {
+			if filepath.Ext(path) == ext {
+				sources = append(sources, Source{Path: path})
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Timings != nil {
+		opts.Timings.Walk += time.Since(walkStart)
+	}
+
+	featureSet, err := ParseSources(ctx, sources, opts)
+	if err != nil {
+		fileErrs, ok := err.(FileErrors)
+		if !ok {
+			return nil, err
+		}
+		errs = append(errs, fileErrs...)
+	}
+	if len(errs) != 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+		return featureSet, errs
+	}
+	return featureSet, nil
+}
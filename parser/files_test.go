@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func writeFeatureFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gorkin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var featureFiles = map[string]string{
+	"a.feature": `Feature: first
+		Scenario: one
+			Given a step
+	`,
+	"nested/b.feature": `Feature: second
+		Scenario: two
+			Given a step
+	`,
+	"nested/broken.feature": `Feature: broken
+		Background:
+			| not | a step |
+	`,
+	"nested/deeper/c.feature": `Feature: third
+		Scenario: three
+			Given a step
+	`,
+	"notes.txt": `Feature: not a feature file`,
+}
+
+func TestParseDir(t *testing.T) {
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+
+	featureSet, err := ParseDir(dir, nil)
+
+	errs, ok := err.(FileErrors)
+	if !ok {
+		t.Fatalf("Expected FileErrors but got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 file error but got %v", len(errs))
+	}
+	expectedPath := filepath.Join(dir, "nested/broken.feature")
+	if errs[0].Path != expectedPath {
+		t.Fatalf("Error path mismatch, expected %v, got %v", expectedPath, errs[0].Path)
+	}
+	if len(errs[0].ParsingErrors) != 1 {
+		t.Fatalf("Expected 1 parser error but got %v", len(errs[0].ParsingErrors))
+	}
+
+	expected := []struct {
+		title string
+		uri   string
+	}{
+		{"first", "a.feature"},
+		{"second", "nested/b.feature"},
+		{"third", "nested/deeper/c.feature"},
+	}
+	if len(featureSet.Features) != len(expected) {
+		t.Fatalf("Featureset length mismatch, expected %v, got %v", len(expected), len(featureSet.Features))
+	}
+	for i, data := range expected {
+		feature := featureSet.Features[i]
+		if feature.Title != data.title {
+			t.Fatalf("Title mismatch, expected %v, got %v", data.title, feature.Title)
+		}
+		if feature.URI != filepath.Join(dir, data.uri) {
+			t.Fatalf("URI mismatch, expected %v, got %v", filepath.Join(dir, data.uri), feature.URI)
+		}
+	}
+}
+
+func TestParseDirExtensions(t *testing.T) {
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+
+	featureSet, err := ParseDir(dir, &ParseOptions{Extensions: []string{".txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(featureSet.Features) != 1 || featureSet.Features[0].Title != "not a feature file" {
+		t.Fatalf("Expected only the .txt feature but got %v", featureSet.Features)
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+
+	missing := filepath.Join(dir, "missing.feature")
+	featureSet, err := ParseFiles(
+		filepath.Join(dir, "a.feature"),
+		missing,
+		filepath.Join(dir, "nested/b.feature"),
+	)
+
+	errs, ok := err.(FileErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("Expected 1 file error but got %v", err)
+	}
+	if errs[0].Path != missing || errs[0].Err == nil {
+		t.Fatalf("Expected read error for %v but got %v", missing, errs[0])
+	}
+	if len(featureSet.Features) != 2 {
+		t.Fatalf("Featureset length mismatch, expected %v, got %v", 2, len(featureSet.Features))
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	featureSet, err := ParseSource("<stdin>", []byte(featureFiles["a.feature"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(featureSet.Features) != 1 || featureSet.Features[0].URI != "<stdin>" {
+		t.Fatalf("Expected one feature with the URI %q but got %v", "<stdin>", featureSet.Features)
+	}
+
+	_, err = ParseSource("broken.feature", []byte(featureFiles["nested/broken.feature"]))
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != "broken.feature" || len(fileErr.ParsingErrors) != 1 {
+		t.Fatalf("Expected 1 parser error for broken.feature but got %v", err)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	for _, name := range []string{"a.feature", "nested/deeper/c.feature", "nested/broken.feature"} {
+		expected, expectedErr := ParseSource(name, []byte(featureFiles[name]))
+		featureSet, err := ParseReader(name, iotest.HalfReader(strings.NewReader(featureFiles[name])))
+		if !reflect.DeepEqual(featureSet, expected) {
+			t.Fatalf("%v: FeatureSet mismatch, expected %+v, got %+v", name, expected, featureSet)
+		}
+		if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+			t.Fatalf("%v: error mismatch, expected %v, got %v", name, expectedErr, err)
+		}
+	}
+
+	// A read error is reported instead of the features read before it
+	r := iotest.TimeoutReader(strings.NewReader(featureFiles["a.feature"] + strings.Repeat("\n", 8192)))
+	featureSet, err := ParseReader("a.feature", r)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Err != iotest.ErrTimeout || featureSet != nil {
+		t.Fatalf("Expected the read error %v but got %v", iotest.ErrTimeout, err)
+	}
+}
+
+func TestParseNilError(t *testing.T) {
+	// The error of a valid file is a nil interface and not a nil *FileError
+	var err error
+	_, err = ParseSource("a.feature", []byte(featureFiles["a.feature"]))
+	if err != nil {
+		t.Fatalf("Expected no error but got %#v", err)
+	}
+
+	dir := writeFeatureFiles(t, featureFiles)
+	defer os.RemoveAll(dir)
+	_, err = ParseFile(filepath.Join(dir, "a.feature"))
+	if err != nil {
+		t.Fatalf("Expected no error but got %#v", err)
+	}
+}
+
+func TestFileErrorMessages(t *testing.T) {
+	_, err := ParseSource("broken.feature", []byte("Feature: broken\n\tScenario: one\n\t\t| a |\n\tScenario: two\n\t\t| b |\n"))
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("Expected a *FileError for broken.feature but got %v", err)
+	}
+	expected := []string{
+		"broken.feature:3:5: Expected token to be a STEP_TYPE but got TABLEDATA",
+		"broken.feature:5:5: Expected token to be a STEP_TYPE but got TABLEDATA",
+	}
+	messages := fileErr.Messages()
+	if len(messages) != len(expected) {
+		t.Fatalf("Messages length mismatch, expected %v, got %v: %v", len(expected), len(messages), messages)
+	}
+	for i, message := range messages {
+		if message != expected[i] {
+			t.Fatalf("Message mismatch, expected %q, got %q", expected[i], message)
+		}
+	}
+
+	readErr := &FileError{Path: "missing.feature", Err: os.ErrNotExist}
+	if readErr.Error() != "missing.feature: file does not exist" {
+		t.Fatalf("Error mismatch, expected %q, got %q", "missing.feature: file does not exist", readErr.Error())
+	}
+}
@@ -15,12 +15,23 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
+	// ruleTags holds the tags read ahead of a Rule keyword while parsing
+	// the scenarios preceding it
+	ruleTags []token.Token
+
+	// comments holds the comment lines read so far
+	comments []token.Token
+
 	errors []ParsingError
 }
 
 // ParsingError is error object representing the Parsing errors
 type ParsingError interface {
 	GetMessage() string
+	// Position returns the line and the column the error was found at
+	Position() (line, column int)
+	// Reason returns the error message without the position
+	Reason() string
 	parserErrorType()
 }
 
@@ -28,25 +39,38 @@ type ParsingError interface {
 type GeneralParserError struct {
 	parser     Parser
 	LineNumber int
+	Column     int
 	Message    string
 }
 
 // GetMessage returns the formatted error message
 func (p *GeneralParserError) GetMessage() string {
 	return fmt.Sprintf(
-		"Parser Error: %v:%v %v",
+		"Parser Error: %v:%v:%v %v",
 		p.parser.l.FilePath,
 		p.LineNumber,
+		p.Column,
 		p.Message,
 	)
 }
 
+// Position returns the line and the column of the error
+func (p *GeneralParserError) Position() (int, int) {
+	return p.LineNumber, p.Column
+}
+
+// Reason returns the error message
+func (p *GeneralParserError) Reason() string {
+	return p.Message
+}
+
 func (p *GeneralParserError) parserErrorType() {}
 
 // PeekError is error object representing peek errors
 type PeekError struct {
 	parser            *Parser
 	LineNumber        int
+	Column            int
 	ExpectedTokenType token.Type
 	ActualToken       token.Token
 }
@@ -54,14 +78,24 @@ type PeekError struct {
 // GetMessage returns the formatted error message
 func (p *PeekError) GetMessage() string {
 	return fmt.Sprintf(
-		"Parser Error: %v:%v Expected token to be %q but got %q",
+		"Parser Error: %v:%v:%v %v",
 		p.parser.l.FilePath,
 		p.LineNumber,
-		p.ExpectedTokenType,
-		p.ActualToken.Type,
+		p.Column,
+		p.Reason(),
 	)
 }
 
+// Position returns the line and the column of the error
+func (p *PeekError) Position() (int, int) {
+	return p.LineNumber, p.Column
+}
+
+// Reason returns the error message
+func (p *PeekError) Reason() string {
+	return fmt.Sprintf("Expected token to be %q but got %q", p.ExpectedTokenType, p.ActualToken.Type)
+}
+
 func (p *PeekError) parserErrorType() {}
 
 // Parser Helper functions
@@ -117,7 +151,65 @@ func (p *Parser) expectPeekTokens(tokens ...token.Type) bool {
 }
 
 func (p *Parser) peekError(t token.Type) {
-	p.errors = append(p.errors, &PeekError{parser: p, LineNumber: p.peekToken.LineNumber, ExpectedTokenType: t})
+	p.errors = append(p.errors, &PeekError{
+		parser:            p,
+		LineNumber:        p.peekToken.LineNumber,
+		Column:            p.peekToken.Column,
+		ExpectedTokenType: t,
+		ActualToken:       p.peekToken,
+	})
+}
+
+// curError records an error for the current token not being of given type
+func (p *Parser) curError(t token.Type) {
+	p.errors = append(p.errors, &PeekError{
+		parser:            p,
+		LineNumber:        p.curToken.LineNumber,
+		Column:            p.curToken.Column,
+		ExpectedTokenType: t,
+		ActualToken:       p.curToken,
+	})
+}
+
+// syncTokens are the tokens the parser resynchronises at after an error
+var syncTokens = []token.Type{
+	token.SCENARIO,
+	token.TAG,
+	token.EXAMPLES,
+	token.RULE,
+	token.EOF,
+}
+
+// synchronize skips the tokens after a parsing error until one of the
+// syncTokens starts a line, so that parsing can continue from there and
+// report the rest of the errors in the input
+func (p *Parser) synchronize() {
+	for !p.curTokenIs(token.EOF) {
+		if !p.curTokenIs(token.NEWLINE) {
+			p.nextToken()
+			continue
+		}
+		p.skipNewLines()
+		for _, t := range syncTokens {
+			if p.curTokenIs(t) {
+				return
+			}
+		}
+	}
+}
+
+// resync recovers from an error in the block that started at the given
+// token. Parsing resumes at the current token if the parser has moved past
+// the start of the block and stopped at a keyword that starts a new block,
+// otherwise the input is skipped until the next sync token.
+func (p *Parser) resync(start token.Token) {
+	if p.curToken.Offset > start.Offset {
+		switch p.curToken.Type {
+		case token.SCENARIO, token.EXAMPLES, token.RULE, token.EOF:
+			return
+		}
+	}
+	p.synchronize()
 }
 
 func (p *Parser) getParserErrors() []string {
@@ -137,12 +229,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.nextToken()
 	p.nextToken()
 
+	if _, ok := token.GetDialect(l.Language); !ok {
+		msg := fmt.Sprintf("Unknown language %q", l.Language)
+		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: 1, Column: 1, Message: msg})
+	}
+
 	return p
 }
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+	if p.peekToken.Type == token.COMMENT && p.startsLine(p.peekToken) {
+		p.comments = append(p.comments, p.peekToken)
+	}
+}
+
+// startsLine checks if only whitespace precedes given token in its line
+func (p *Parser) startsLine(t token.Token) bool {
+	return strings.TrimSpace(p.l.Slice(t.Offset-t.Column+1, t.Offset)) == ""
 }
 
 func (p *Parser) skipNewLines() {
@@ -155,13 +260,10 @@ func (p *Parser) skipNewLines() {
 
 // Parse Parses the parser object and returns a featureSet
 func (p *Parser) Parse() *object.FeatureSet {
-
-	// TODO:Implement Parsing Multiple features from different Files
-
 	featureSet := &object.FeatureSet{}
 	p.skipNewLines()
 	if !(p.curTokenIs(token.FEATURE) || p.curTokenIs(token.TAG)) {
-		p.peekError(token.FEATURE)
+		p.curError(token.FEATURE)
 		return nil
 	}
 	feature := p.ParseFeature()
@@ -173,79 +275,188 @@ func (p *Parser) Parse() *object.FeatureSet {
 }
 
 // ParseFeature parses Feature from the current position in the parser
+//
+// Errors inside the Feature do not stop the parsing, the returned Feature
+// contains every Background, Scenario and Rule that was parsed successfully
 func (p *Parser) ParseFeature() *object.Feature {
-	feature := &object.Feature{}
-	var tags []string
+	feature := &object.Feature{URI: p.l.FilePath, Language: p.l.Language}
 	p.skipNewLines()
 	if p.curTokenIs(token.TAG) {
-		tags = p.ParseTags()
-		feature.Tags = tags
-		if tags == nil {
-			return nil
-		}
+		feature.TagTokens = p.parseTagTokens()
+		feature.Tags = tagLiterals(feature.TagTokens)
 	}
 	p.skipNewLines()
 	if !p.curTokenIs(token.FEATURE) {
-		p.peekError(token.FEATURE)
+		p.curError(token.FEATURE)
 		return nil
 	}
 	feature.Token = p.curToken
 	if !p.expectPeek(token.COLON) {
-		p.peekError(token.COLON)
 		return nil
 	}
 	p.nextToken()
-	for !p.curTokenIs(token.NEWLINE) {
-		feature.Title += p.curToken.Literal
-		p.nextToken()
+	feature.Title = p.parseTitle()
+
+	p.skipNewLines()
+	feature.Description = p.parseDescription(blockTokens)
+
+	feature.Background = nil
+	if p.curTokenIs(token.BACKGROUND) {
+		start := p.curToken
+		feature.Background = p.ParseBackground()
+		if feature.Background == nil {
+			p.resync(start)
+		}
 	}
+	p.skipNewLines()
 
+	if !(p.curTokenIs(token.RULE) || p.curTokenIs(token.EOF)) {
+		feature.Scenarios = p.ParseScenarioTypeSet()
+	}
 	p.skipNewLines()
 
-	for !(p.curTokenIs(token.BACKGROUND) ||
-		p.curTokenIs(token.SCENARIO) ||
-		p.curTokenIs(token.TAG)) {
-		p.nextToken()
+	for !p.curTokenIs(token.EOF) {
+		start := p.curToken
+		rule := p.ParseRule()
+		if rule == nil {
+			p.resync(start)
+		} else {
+			feature.Rules = append(feature.Rules, *rule)
+		}
 		p.skipNewLines()
 	}
+	feature.Comments = p.comments
+	return feature
+}
+
+// ParseRule parses Rule from the current position in the parser
+func (p *Parser) ParseRule() *object.Rule {
+	rule := &object.Rule{TagTokens: p.ruleTags}
+	p.ruleTags = nil
+	p.skipNewLines()
+	if p.curTokenIs(token.TAG) {
+		rule.TagTokens = p.parseTagTokens()
+	}
+	rule.Tags = tagLiterals(rule.TagTokens)
+	p.skipNewLines()
+	if !p.curTokenIs(token.RULE) {
+		p.curError(token.RULE)
+		return nil
+	}
+	rule.Token = p.curToken
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	rule.Title = p.parseTitle()
+	p.skipNewLines()
+	rule.Description = p.parseDescription(blockTokens)
 
-	feature.Background = nil
 	if p.curTokenIs(token.BACKGROUND) {
-		background := p.ParseBackground()
-		if background == nil {
-			return nil
+		start := p.curToken
+		rule.Background = p.ParseBackground()
+		if rule.Background == nil {
+			p.resync(start)
 		}
-		feature.Background = background
 	}
 	p.skipNewLines()
 
-	var scenarios []object.ScenarioType
-	if p.curTokenIs(token.SCENARIO) || p.curTokenIs(token.TAG) {
-		scenarios = p.ParseScenarioTypeSet()
+	if !(p.curTokenIs(token.RULE) || p.curTokenIs(token.EOF)) {
+		rule.Scenarios = p.ParseScenarioTypeSet()
+	}
+	return rule
+}
+
+// parseTitle reads the rest of the line as the title of a block
+func (p *Parser) parseTitle() string {
+	start := p.curToken.Offset
+	for !(p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.EOF)) {
+		p.nextToken()
+	}
+	return strings.TrimSpace(p.l.Slice(start, p.curToken.Offset))
+}
+
+// blockTokens are the tokens that end the description of a Feature or a Rule
+var blockTokens = []token.Type{
+	token.BACKGROUND,
+	token.SCENARIO,
+	token.RULE,
+	token.TAG,
+	token.EOF,
+}
+
+// scenarioTokens are the tokens that end the description of a Scenario or an
+// Examples block
+var scenarioTokens = append([]token.Type{
+	token.EXAMPLES,
+	token.TABLEDATA,
+	token.GIVEN,
+	token.WHEN,
+	token.THEN,
+	token.AND,
+	token.BUT,
+}, blockTokens...)
+
+// parseDescription reads the free text lines until one of the given tokens
+// starts a line, keeping the original line breaks and indentation but
+// leaving out the comments and the trailing blank lines
+func (p *Parser) parseDescription(end []token.Type) string {
+	var lines []string
+	blank := 0
+	for {
+		for _, t := range end {
+			if p.curTokenIs(t) {
+				return strings.Join(lines, "\n")
+			}
+		}
+		switch p.curToken.Type {
+		case token.NEWLINE:
+			if len(lines) > 0 {
+				blank++
+			}
+			p.nextToken()
+		case token.COMMENT:
+			p.nextToken()
+			p.nextToken()
+		default:
+			// Only whitespace can precede the first token in the line, so
+			// the line starts Column-1 bytes before the token
+			start := p.curToken.Offset - p.curToken.Column + 1
+			for !(p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.EOF)) {
+				p.nextToken()
+			}
+			for ; blank > 0; blank-- {
+				lines = append(lines, "")
+			}
+			lines = append(lines, strings.TrimRight(p.l.Slice(start, p.curToken.Offset), " \t\r"))
+			if p.curTokenIs(token.NEWLINE) {
+				p.nextToken()
+			}
+		}
 	}
-	feature.Scenarios = scenarios
-	return feature
 }
 
 // ParseBackground parses Background object from the current position in the parser
 func (p *Parser) ParseBackground() *object.Background {
 	p.skipNewLines()
-	background := &object.Background{}
 	if !p.curTokenIs(token.BACKGROUND) {
-		p.peekError(token.BACKGROUND)
+		p.curError(token.BACKGROUND)
 		return nil
 	}
+	background := &object.Background{
+		Keyword:    p.curToken.Literal,
+		LineNumber: p.curToken.LineNumber,
+		Column:     p.curToken.Column,
+	}
 	if !p.expectPeekTokens(token.COLON) {
 		return nil
 	}
 	p.nextToken()
-	if p.curTokenIs(token.STEPBODY) {
-		p.nextToken()
-	}
+	background.Title = p.parseTitle()
 	p.skipNewLines()
 	if !isStepToken(p.curToken) {
 		msg := fmt.Sprintf("Expected token to be a STEP_TYPE but got %s", p.curToken.Type)
-		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Message: msg})
+		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Column: p.curToken.Column, Message: msg})
 		return nil
 	}
 	steps := p.ParseBlockSteps()
@@ -263,11 +474,15 @@ func (p *Parser) ParseBlockSteps() []object.Step {
 	p.skipNewLines()
 	if !isStepToken(p.curToken) {
 		msg := fmt.Sprintf("Expected token to be a STEP_TYPE but got %s", p.curToken.Type)
-		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Message: msg})
+		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Column: p.curToken.Column, Message: msg})
 		return nil
 	}
 	for isStepToken(p.curToken) {
-		steps = append(steps, *p.ParseStep())
+		step := p.ParseStep()
+		if step == nil {
+			return nil
+		}
+		steps = append(steps, *step)
 		p.skipNewLines()
 	}
 	return steps
@@ -275,90 +490,119 @@ func (p *Parser) ParseBlockSteps() []object.Step {
 
 // ParseTags parses collection of Tag from the current position in the parser
 func (p *Parser) ParseTags() []string {
-	tags := []string{}
+	return tagLiterals(p.parseTagTokens())
+}
+
+func (p *Parser) parseTagTokens() []token.Token {
+	tags := []token.Token{}
 	for p.curTokenIs(token.TAG) {
-		tags = append(tags, p.curToken.Literal)
+		tags = append(tags, p.curToken)
 		p.nextToken()
 		p.skipNewLines()
 	}
 	return tags
 }
 
+func tagLiterals(tags []token.Token) []string {
+	literals := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		literals = append(literals, tag.Literal)
+	}
+	return literals
+}
+
 // ParseScenarioTypeSet parses collection of ScenarioType from the current position in the parser
+//
+// The scenarios are parsed until the next Rule or the end of the input, a
+// scenario with errors is left out and parsing resumes from the next
+// Scenario, Tag, Examples or Rule keyword
 func (p *Parser) ParseScenarioTypeSet() []object.ScenarioType {
 	p.skipNewLines()
-	if !(p.curTokenIs(token.SCENARIO) || p.curTokenIs(token.TAG)) {
-		p.peekError(p.curToken.Type)
-		return nil
-	}
 	scenarios := []object.ScenarioType{}
-	lastTags := []string{}
-	for p.curTokenIs(token.SCENARIO) || p.curTokenIs(token.TAG) {
-		lastScenario := p.ParseScenarioType(lastTags)
+	lastTags := []token.Token{}
+	for !(p.curTokenIs(token.RULE) || p.curTokenIs(token.EOF)) {
+		if p.curTokenIs(token.TAG) {
+			lastTags = append(lastTags, p.parseTagTokens()...)
+			p.skipNewLines()
+			continue
+		}
+		if !p.curTokenIs(token.SCENARIO) {
+			p.curError(token.SCENARIO)
+			p.synchronize()
+			lastTags = []token.Token{}
+			continue
+		}
+		start := p.curToken
+		lastScenario := p.parseScenarioType(lastTags)
+		lastTags = []token.Token{}
 		if lastScenario == nil {
-			return nil
+			p.resync(start)
+			continue
 		}
 		scenarios = append(scenarios, lastScenario)
 		p.skipNewLines()
 
+		// Tags after the last Examples of an outline belong to whatever follows it
 		lastOutline, ok := lastScenario.(*object.ScenarioOutline)
-		if !ok {
-			lastTags = []string{}
-			continue
-		}
-		if len(lastOutline.TableTags) != len(lastOutline.Tables) {
-			if len(lastOutline.TableTags) != len(lastOutline.Tables)+1 {
-				return nil
-			}
-
-			lastTags = lastOutline.TableTags[len(lastOutline.TableTags)-1]
-			lastOutline.TableTags = lastOutline.TableTags[:len(lastOutline.TableTags)-1]
-		} else {
-			lastTags = []string{}
+		if ok && len(lastOutline.TableTags) > len(lastOutline.Tables) {
+			lastTags = lastOutline.TableTagTokens[len(lastOutline.Tables)]
+			lastOutline.TableTags = lastOutline.TableTags[:len(lastOutline.Tables)]
+			lastOutline.TableTagTokens = lastOutline.TableTagTokens[:len(lastOutline.Tables)]
 		}
 	}
 
+	if p.curTokenIs(token.RULE) {
+		p.ruleTags = lastTags
+	}
 	return scenarios
 }
 
 // ParseScenarioType parses a ScenarioType from the current position in the parser
 func (p *Parser) ParseScenarioType(lastTags []string) object.ScenarioType {
+	var tags []token.Token
+	for _, tag := range lastTags {
+		tags = append(tags, token.Token{Type: token.TAG, Literal: tag})
+	}
+	return p.parseScenarioType(tags)
+}
+
+func (p *Parser) parseScenarioType(lastTags []token.Token) object.ScenarioType {
 	tags := lastTags
 	p.skipNewLines()
 	if p.curTokenIs(token.TAG) {
-		tags = p.ParseTags()
-		if len(lastTags) > 0 {
-			return nil
-		}
-		if tags == nil {
-			return nil
-		}
+		tags = append(append([]token.Token{}, lastTags...), p.parseTagTokens()...)
 	}
 	p.skipNewLines()
 	if !p.curTokenIs(token.SCENARIO) {
-		p.peekError(token.SCENARIO)
+		p.curError(token.SCENARIO)
 		return nil
 	}
 	lineNumber := p.curToken.LineNumber
+	column := p.curToken.Column
+	keyword := p.curToken.Literal
 	outLineType := false
 	if p.peekTokenIs(token.OUTLINE) {
 		outLineType = true
+		start := p.curToken.Offset
 		p.nextToken()
+		keyword = p.l.Slice(start, p.curToken.Offset+len(p.curToken.Literal))
 	}
 	if !p.expectPeek(token.COLON) {
-		p.peekError(token.COLON)
 		return nil
 	}
 	p.nextToken()
-	var title string
-	for !p.curTokenIs(token.NEWLINE) {
-		title += p.curToken.Literal
-		p.nextToken()
-	}
+	title := p.parseTitle()
 	p.skipNewLines()
+	description := p.parseDescription(scenarioTokens)
 	steps := p.ParseBlockSteps()
+	if steps == nil {
+		return nil
+	}
 	if outLineType {
-		tableTags := [][]string{}
+		tableTags := [][]token.Token{}
+		tableTokens := []token.Token{}
+		tableNames := []string{}
+		tableDescriptions := []string{}
 		tables := []object.Table{}
 
 		p.skipNewLines()
@@ -367,59 +611,80 @@ func (p *Parser) ParseScenarioType(lastTags []string) object.ScenarioType {
 			p.skipNewLines()
 			if !(p.curTokenIs(token.TAG) || p.curTokenIs(token.EXAMPLES)) {
 				if len(tables) < 1 {
+					p.curError(token.EXAMPLES)
 					return nil
 				}
 				break
 			}
 
 			if p.curTokenIs(token.TAG) {
-				tableTag := p.ParseTags()
-				if tableTag == nil {
-					return nil
-				}
+				tableTags = append(tableTags, p.parseTagTokens())
 				p.skipNewLines()
-				tableTags = append(tableTags, tableTag)
 			} else {
-				tableTags = append(tableTags, []string{})
+				tableTags = append(tableTags, []token.Token{})
 			}
 			p.skipNewLines()
 
 			if !p.curTokenIs(token.EXAMPLES) {
+				if len(tables) < 1 {
+					p.curError(token.EXAMPLES)
+					return nil
+				}
 				break
 			}
+			tableTokens = append(tableTokens, p.curToken)
 			if !p.expectPeek(token.COLON) {
-				p.peekError(token.COLON)
-				return nil
-			}
-			if !p.expectPeek(token.NEWLINE) {
-				p.peekError(token.NEWLINE)
 				return nil
 			}
+			p.nextToken()
+			tableNames = append(tableNames, p.parseTitle())
 
 			p.skipNewLines()
+			tableDescriptions = append(tableDescriptions, p.parseDescription(scenarioTokens))
 			if !p.curTokenIs(token.TABLEDATA) {
-				p.peekError(p.curToken.Type)
+				p.curError(token.TABLEDATA)
 				return nil
 			}
 
 			table := p.ParseTable()
+			if table == nil {
+				return nil
+			}
 			tables = append(tables, *table)
 		}
 
-		return &object.ScenarioOutline{
-			Steps:        steps,
-			Tags:         tags,
-			ScenarioText: title,
-			Tables:       tables,
-			LineNumber:   lineNumber,
-			TableTags:    tableTags,
+		var tableTagLiterals [][]string
+		for _, tags := range tableTags {
+			tableTagLiterals = append(tableTagLiterals, tagLiterals(tags))
+		}
+		outline := &object.ScenarioOutline{
+			Steps:             steps,
+			Tags:              tagLiterals(tags),
+			TagTokens:         tags,
+			Keyword:           keyword,
+			ScenarioText:      title,
+			Description:       description,
+			Tables:            tables,
+			TableNames:        tableNames,
+			LineNumber:        lineNumber,
+			Column:            column,
+			TableTags:         tableTagLiterals,
+			TableDescriptions: tableDescriptions,
+			TableTokens:       tableTokens,
+			TableTagTokens:    tableTags,
 		}
+		outline.IndexPlaceholders()
+		return outline
 	}
 	return &object.Scenario{
 		Steps:        steps,
-		Tags:         tags,
+		Tags:         tagLiterals(tags),
+		TagTokens:    tags,
+		Keyword:      keyword,
 		ScenarioText: title,
+		Description:  description,
 		LineNumber:   lineNumber,
+		Column:       column,
 	}
 }
 
@@ -433,7 +698,10 @@ func (p *Parser) ParseStep() *object.Step {
 	p.skipNewLines()
 	if token.IsStepToken(p.curToken.Type) {
 		step.Token = p.curToken
+		step.LineNumber = p.curToken.LineNumber
+		step.Column = p.curToken.Column
 		p.nextToken()
+		start := p.curToken.Offset
 		for !(p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.EOF)) {
 			switch p.curToken.Type {
 			case token.NUMBER:
@@ -462,10 +730,11 @@ func (p *Parser) ParseStep() *object.Step {
 			}
 		}
 		step.StepText = strings.TrimSpace(step.StepText)
+		step.Text = strings.TrimSpace(p.l.Slice(start, p.curToken.Offset))
 		p.nextToken()
 	} else {
 		msg := fmt.Sprintf("Expected token to be a STEP_TYPE but got %s", p.curToken.Type)
-		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Message: msg})
+		p.errors = append(p.errors, &GeneralParserError{parser: *p, LineNumber: p.curToken.LineNumber, Column: p.curToken.Column, Message: msg})
 		return nil
 	}
 	if p.curTokenIs(token.TABLEDATA) {
@@ -478,31 +747,61 @@ func (p *Parser) ParseStep() *object.Step {
 	if p.curTokenIs(token.PYSTRING) {
 		step.StepText = step.StepText + "\n{{s}}"
 		step.Data = append(step.Data, p.curToken.Literal)
+		delimiter, mediaType := p.docStringFence()
+		step.DocString = &object.DocString{
+			Content:    p.curToken.Literal,
+			Delimiter:  delimiter,
+			MediaType:  mediaType,
+			LineNumber: p.curToken.LineNumber,
+			Column:     p.curToken.Column,
+		}
 		p.nextToken()
 	}
 	return step
 }
 
+// docStringFence returns the delimiter and the media type from the opening
+// line of the DocString at the current token
+func (p *Parser) docStringFence() (string, string) {
+	line := p.l.Slice(p.curToken.Offset, p.peekToken.Offset)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if len(line) < 3 {
+		return `"""`, ""
+	}
+	return line[:3], strings.TrimSpace(line[3:])
+}
+
 // ParseTable parses a Table from the current position in the parser
 func (p *Parser) ParseTable() *object.Table {
 	var table object.Table
 	var tmp []object.TableData
 	p.skipNewLines()
 	if !p.curTokenIs(token.TABLEDATA) {
-		p.peekError(token.TABLEDATA)
+		p.curError(token.TABLEDATA)
 		return nil
 	}
 
 	for p.curTokenIs(token.TABLEDATA) {
 
-		tmp = []object.TableData{}
+		// The rows usually have as many cells as the first one
+		size := 4
+		if len(table) > 0 {
+			size = len(table[0])
+		}
+		tmp = make([]object.TableData, 0, size)
 		for !(p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.EOF)) {
 
 			if !p.curTokenIs(token.TABLEDATA) {
-				p.peekError(token.TABLEDATA)
+				p.curError(token.TABLEDATA)
 				return nil
 			}
-			tmp = append(tmp, object.TableData{p.curToken.Literal, p.curToken.LineNumber})
+			tmp = append(tmp, object.TableData{
+				Literal:    p.curToken.Literal,
+				LineNumber: p.curToken.LineNumber,
+				Column:     p.curToken.Column,
+			})
 			p.nextToken()
 		}
 
@@ -1,8 +1,14 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/dpakach/gorkin/lexer"
 	"github.com/dpakach/gorkin/object"
@@ -414,7 +420,7 @@ func TestParseScenarioOutlineMultipleExamples2(t *testing.T) {
 		| value1 | v1    |
 
 	@tag2 @tagworld
-	Examples:
+	Examples: named <examples>
 		| data1  | data2 |
 		| value2 | 5     |
 	`, stepInput1)
@@ -465,6 +471,11 @@ func TestParseScenarioOutlineMultipleExamples2(t *testing.T) {
 			t.Fatalf("Table tags mismatch, expected %v, got %v", expectedTableTags[i], table)
 		}
 	}
+
+	expectedNames := []string{"", "named <examples>"}
+	if !areArrayEqual(scenario.TableNames, expectedNames) {
+		t.Fatalf("Table names mismatch, expected %q, got %q", expectedNames, scenario.TableNames)
+	}
 }
 
 func TestParseBackground(t *testing.T) {
@@ -893,10 +904,14 @@ func TestTableGetRows(t *testing.T) {
 		t.Fatalf("Expected table to be %v but got %v", expectedTable, parsed)
 	}
 
-	if len(parsed.GetHash()) != 2 {
-		t.Fatalf("Expected table hash length to be %v but got %v", 2, len(parsed.GetHash()))
+	hash, err := parsed.GetHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hash) != 2 {
+		t.Fatalf("Expected table hash length to be %v but got %v", 2, len(hash))
 	}
-	for i, item := range parsed.GetHash() {
+	for i, item := range hash {
 		if !areMapEqual(item, expectedHash[i]) {
 			t.Fatalf("Expected table hash to be %v but got %v", expectedHash[i], item)
 		}
@@ -912,3 +927,626 @@ func TestTableGetRows(t *testing.T) {
 		}
 	}
 }
+
+func TestParsingFeatureWithRules(t *testing.T) {
+	input := fmt.Sprintf(`
+	Feature: This is a feature
+
+		Background:
+			%v
+
+		Scenario: scenario outside rule
+			%v
+
+		@ruleTag
+		Rule: first rule
+			Some description about the rule
+
+			Background:
+				%v
+
+			Scenario: first rule scenario
+				%v
+
+		@anotherRule
+		Rule: second rule
+
+			@tag42
+			Scenario Outline: second rule outline
+				%v
+				Examples:
+				 | data |
+				 | row  |
+
+			Scenario: second rule scenario
+				%v
+	`, stepInput1, stepInput2, stepInput3, stepInput2, stepInput3, stepInput3)
+
+	l := lexer.New(input)
+	p := New(l)
+
+	feature := p.ParseFeature()
+	checkParserErrors(t, p)
+
+	if len(feature.Scenarios) != 1 {
+		t.Fatalf("Expected number of scenarios to be 1 but got %v", len(feature.Scenarios))
+	}
+	if len(feature.Rules) != 2 {
+		t.Fatalf("Expected number of rules to be 2 but got %v", len(feature.Rules))
+	}
+
+	expected := []struct {
+		title       string
+		description string
+		tags        []string
+		background  bool
+		scenarios   int
+	}{
+		{
+			title:       "first rule",
+			description: "\t\t\tSome description about the rule",
+			tags:        []string{"ruleTag"},
+			background:  true,
+			scenarios:   1,
+		},
+		{
+			title:     "second rule",
+			tags:      []string{"anotherRule"},
+			scenarios: 2,
+		},
+	}
+
+	for i, data := range expected {
+		rule := feature.Rules[i]
+		if rule.Title != data.title {
+			t.Fatalf("Title mismatch, expected %v, got %v", data.title, rule.Title)
+		}
+		if rule.Description != data.description {
+			t.Fatalf("Description mismatch, expected %q, got %q", data.description, rule.Description)
+		}
+		if !areArrayEqual(rule.Tags, data.tags) {
+			t.Fatalf("Tags mismatch, expected %v, got %v", data.tags, rule.Tags)
+		}
+		if (rule.Background != nil) != data.background {
+			t.Fatalf("Background mismatch, expected %v, got %v", data.background, rule.Background)
+		}
+		if len(rule.Scenarios) != data.scenarios {
+			t.Fatalf("Expected number of scenarios to be %v but got %v", data.scenarios, len(rule.Scenarios))
+		}
+	}
+
+	outline, ok := feature.Rules[1].Scenarios[0].(*object.ScenarioOutline)
+	if !ok {
+		t.Fatalf("Type mismatch, expected Scenario Outline but not got")
+	}
+	if !areArrayEqual(outline.Tags, []string{"tag42"}) {
+		t.Fatalf("Tags mismatch, expected %v, got %v", []string{"tag42"}, outline.Tags)
+	}
+
+	scenarios := feature.GetScenarios()
+	expectedSteps := []int{6, 8, 5, 5}
+	if len(scenarios) != len(expectedSteps) {
+		t.Fatalf("Expected number of scenarios to be %v but got %v", len(expectedSteps), len(scenarios))
+	}
+	for i, n := range expectedSteps {
+		if len(scenarios[i].Steps) != n {
+			t.Fatalf("Steps length mismatch for %q, expected %v, got %v", scenarios[i].ScenarioText, n, len(scenarios[i].Steps))
+		}
+	}
+}
+
+func TestParsingFeatureDialect(t *testing.T) {
+	input := `# language: de
+	@cool
+	Funktionalität: Ein Feature
+
+		Grundlage:
+			Angenommen ein Hintergrund
+
+		Szenariogrundriss: Ein Szenario
+			Wenn ich <wert> eingebe
+			Dann passiert etwas
+			Aber nicht mehr
+
+			Beispiele:
+				| wert |
+				| 5    |
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	feature := p.ParseFeature()
+	checkParserErrors(t, p)
+
+	if feature.Title != "Ein Feature" {
+		t.Fatalf("Title mismatch, expected %v, got %v", "Ein Feature", feature.Title)
+	}
+	if feature.Background == nil || len(feature.Background.Steps) != 1 {
+		t.Fatalf("Expected background with 1 step but got %v", feature.Background)
+	}
+	if len(feature.Scenarios) != 1 {
+		t.Fatalf("Expected number of scenarios to be 1 but got %v", len(feature.Scenarios))
+	}
+	outline, ok := feature.Scenarios[0].(*object.ScenarioOutline)
+	if !ok {
+		t.Fatalf("Type mismatch, expected Scenario Outline but not got")
+	}
+	expectedTypes := []token.Type{token.WHEN, token.THEN, token.BUT}
+	for i, step := range outline.Steps {
+		if step.Token.Type != expectedTypes[i] {
+			t.Fatalf("Expected Type to be %q, but got %q", expectedTypes[i], step.Token.Type)
+		}
+	}
+	if len(outline.GetScenarios()) != 1 {
+		t.Fatalf("Expected number of examples to be 1 but got %v", len(outline.GetScenarios()))
+	}
+}
+
+func TestParsingUnknownLanguage(t *testing.T) {
+	input := `# language: xx
+	Feature: unknown language
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.Parse()
+
+	if len(p.Errors()) != 1 {
+		t.Fatalf("Expected 1 parser error but got %v", len(p.Errors()))
+	}
+	expected := `Parser Error: :1:1 Unknown language "xx"`
+	if p.Errors()[0].GetMessage() != expected {
+		t.Fatalf("Error message mismatch, expected %q, got %q", expected, p.Errors()[0].GetMessage())
+	}
+}
+
+func TestParsingLocations(t *testing.T) {
+	input := `Feature: Ünïcödé
+  Scenario: 日本語 🎉
+    Given I order 2 crème brûlée for "Zoë"
+      | naïve | 👍🏽 |`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	feature := p.ParseFeature()
+	checkParserErrors(t, p)
+
+	scenario := feature.Scenarios[0].(*object.Scenario)
+	if scenario.ScenarioText != "日本語 🎉" {
+		t.Fatalf("Title mismatch, expected %q, got %q", "日本語 🎉", scenario.ScenarioText)
+	}
+	if scenario.LineNumber != 2 || scenario.Column != 3 {
+		t.Fatalf("Scenario location mismatch, expected 2:3, got %v:%v", scenario.LineNumber, scenario.Column)
+	}
+
+	step := scenario.Steps[0]
+	if step.StepText != "I order {{d}} crème brûlée for {{s}}" {
+		t.Fatalf("Expected step text to be %q, but got %q", "I order {{d}} crème brûlée for {{s}}", step.StepText)
+	}
+	if step.LineNumber != 3 || step.Column != 5 {
+		t.Fatalf("Step location mismatch, expected 3:5, got %v:%v", step.LineNumber, step.Column)
+	}
+
+	expected := []object.TableData{
+		{Literal: "naïve", LineNumber: 4, Column: 9},
+		{Literal: "👍🏽", LineNumber: 4, Column: 17},
+	}
+	for i, data := range step.Table[0] {
+		if data != expected[i] {
+			t.Fatalf("Table data mismatch, expected %v, got %v", expected[i], data)
+		}
+	}
+}
+
+func TestParsingDocStrings(t *testing.T) {
+	input := "Feature: DocStrings\n  Scenario: fences\n    Given a step\n      \"\"\"json \n      {}\n      \"\"\"\n    And a step\n      ```\n      text\n      ```\n"
+
+	l := lexer.New(input)
+	p := New(l)
+
+	feature := p.ParseFeature()
+	checkParserErrors(t, p)
+
+	expected := []object.DocString{
+		{Content: "{}", Delimiter: `"""`, MediaType: "json", LineNumber: 4, Column: 7},
+		{Content: "text", Delimiter: "```", LineNumber: 8, Column: 7},
+	}
+	for i, step := range feature.Scenarios[0].(*object.Scenario).Steps {
+		if step.DocString == nil || *step.DocString != expected[i] {
+			t.Fatalf("DocString mismatch, expected %+v, got %+v", expected[i], step.DocString)
+		}
+	}
+}
+
+func TestParsingErrorLocation(t *testing.T) {
+	input := `Feature: test
+	  Background:
+	    | not | a step |
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseFeature()
+
+	if len(p.Errors()) != 1 {
+		t.Fatalf("Expected 1 parser error but got %v", len(p.Errors()))
+	}
+	expected := `Parser Error: :3:8 Expected token to be a STEP_TYPE but got TABLEDATA`
+	if p.Errors()[0].GetMessage() != expected {
+		t.Fatalf("Error message mismatch, expected %q, got %q", expected, p.Errors()[0].GetMessage())
+	}
+}
+
+func TestParsingDescriptions(t *testing.T) {
+	input := `Feature: described feature
+  As a "user" with 2 accounts
+    I want my: description
+
+  # not a part of the description
+  to stay intact
+
+
+  Scenario: described scenario
+    Scenario prose
+
+    Given a step
+
+  Scenario Outline: described outline
+    Outline prose
+    Given a <thing>
+
+    Examples:
+      Examples prose
+      | thing |
+      | one   |
+
+    @tagged
+    Examples:
+      | thing |
+      | two   |
+`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	feature := p.ParseFeature()
+	checkParserErrors(t, p)
+
+	expected := "  As a \"user\" with 2 accounts\n    I want my: description\n\n  to stay intact"
+	if feature.Description != expected {
+		t.Fatalf("Description mismatch, expected %q, got %q", expected, feature.Description)
+	}
+
+	scenario := feature.Scenarios[0].(*object.Scenario)
+	if scenario.Description != "    Scenario prose" {
+		t.Fatalf("Description mismatch, expected %q, got %q", "    Scenario prose", scenario.Description)
+	}
+	if len(scenario.Steps) != 1 {
+		t.Fatalf("Steps length mismatch, expected 1, got %v", len(scenario.Steps))
+	}
+
+	outline := feature.Scenarios[1].(*object.ScenarioOutline)
+	if outline.Description != "    Outline prose" {
+		t.Fatalf("Description mismatch, expected %q, got %q", "    Outline prose", outline.Description)
+	}
+	expectedTables := []string{"      Examples prose", ""}
+	if !areArrayEqual(outline.TableDescriptions, expectedTables) {
+		t.Fatalf("Examples descriptions mismatch, expected %q, got %q", expectedTables, outline.TableDescriptions)
+	}
+	if len(outline.Tables) != 2 {
+		t.Fatalf("Expected 2 examples tables but got %v", len(outline.Tables))
+	}
+}
+
+func TestParsingErrorRecovery(t *testing.T) {
+	input := `Feature: feature with errors
+
+	Scenario: first good scenario
+		Given a step
+
+	Scenario broken title
+		Given a step
+
+	Scenario: second good scenario
+		Given a step
+
+	Scenario: broken steps
+		| not | a step |
+
+	@tagged
+	Scenario: third good scenario
+		Given a step
+
+	Examples:
+		| orphan |
+
+	Scenario Outline: broken outline
+		Given a <thing>
+
+	Rule: a rule
+		Scenario: rule scenario
+			Given a step
+
+		Scenario Outline: no examples
+			Given a <thing>
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	featureSet := p.Parse()
+
+	expectedErrors := []string{
+		`Parser Error: :6:2 Expected token to be "Scenario" but got "STEP_TEXT"`,
+		`Parser Error: :13:5 Expected token to be a STEP_TYPE but got TABLEDATA`,
+		`Parser Error: :19:2 Expected token to be "Scenario" but got "Examples"`,
+		`Parser Error: :25:2 Expected token to be "Examples" but got "Rule"`,
+		`Parser Error: :31:2 Expected token to be "Examples" but got "EOF"`,
+	}
+	errors := p.getParserErrors()
+	if !areArrayEqual(errors, expectedErrors) {
+		t.Fatalf("Errors mismatch, expected %q, got %q", expectedErrors, errors)
+	}
+
+	if featureSet == nil || len(featureSet.Features) != 1 {
+		t.Fatal("Expected a partial feature but got nil")
+	}
+	feature := featureSet.Features[0]
+
+	var titles []string
+	for _, scenario := range feature.GetScenarios() {
+		titles = append(titles, scenario.ScenarioText)
+	}
+	expectedTitles := []string{"first good scenario", "second good scenario", "third good scenario", "rule scenario"}
+	if !areArrayEqual(titles, expectedTitles) {
+		t.Fatalf("Scenarios mismatch, expected %q, got %q", expectedTitles, titles)
+	}
+	third := feature.Scenarios[2].(*object.Scenario)
+	if !areArrayEqual(third.Tags, []string{"tagged"}) {
+		t.Fatalf("Tags mismatch, expected %v, got %v", []string{"tagged"}, third.Tags)
+	}
+}
+
+// TestParsingNeverPanics parses randomly mutated feature files, the parser
+// must report the broken input as errors instead of panicking
+func TestParsingNeverPanics(t *testing.T) {
+	input := `@coolTag
+Feature: test
+	Background:
+		When I run background
+			| also | with |
+			| a	| table |
+
+	@tag
+	Scenario: example scenario
+		When I do something "with" 2 strings
+		Then something happens
+		"""
+		doc
+		"""
+
+	Rule: a rule
+	Scenario Outline: another example scenario
+		When i do something <task>
+		Then something "<result>" happens
+
+		@x
+		Examples:
+			| task | result |
+			| good | yes    |
+			| bad  | no     |
+`
+	pieces := []string{
+		":", "|", "\"", "\"\"\"", "@", "<", ">", "\n", "#", " ", "~", "\xff",
+		"Feature:", "Background:", "Scenario:", "Scenario Outline:", "Examples:", "Rule:", "Given ",
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		mutated := input
+		for j := 0; j < 1+r.Intn(5); j++ {
+			pos := r.Intn(len(mutated) + 1)
+			if r.Intn(2) == 0 {
+				end := pos + r.Intn(10)
+				if end > len(mutated) {
+					end = len(mutated)
+				}
+				mutated = mutated[:pos] + mutated[end:]
+			} else {
+				mutated = mutated[:pos] + pieces[r.Intn(len(pieces))] + mutated[pos:]
+			}
+		}
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					t.Fatalf("Parser panicked with %v for input %q", err, mutated)
+				}
+			}()
+			featureSet := New(lexer.New(mutated)).Parse()
+			if featureSet == nil {
+				return
+			}
+			for _, feature := range featureSet.Features {
+				feature.GetScenarios()
+			}
+		}()
+	}
+}
+
+func TestParsingKeywordsAndTitles(t *testing.T) {
+	input := `# a comment
+@tag1 @tag2
+Feature: the "quoted" feature 2
+	Background:
+		Given a step with 2 "values"
+			"""
+			doc string
+			"""
+
+	Scenario Outline: outline <value>
+		When I use <value>
+
+		@examples
+		Examples:
+			| value |
+			| 1     |
+`
+	l := lexer.New(input)
+	p := New(l)
+	featureSet := p.Parse()
+	checkParserErrors(t, p)
+	feature := featureSet.Features[0]
+
+	if feature.Title != `the "quoted" feature 2` {
+		t.Fatalf("Title mismatch, expected %q, got %q", `the "quoted" feature 2`, feature.Title)
+	}
+	if feature.Language != "en" {
+		t.Fatalf("Language mismatch, expected %v, got %v", "en", feature.Language)
+	}
+	if len(feature.Comments) != 1 || feature.Comments[0].Literal != "a comment" {
+		t.Fatalf("Comments mismatch, got %v", feature.Comments)
+	}
+	if len(feature.TagTokens) != 2 || feature.TagTokens[1].Literal != "tag2" || feature.TagTokens[1].Column != 7 {
+		t.Fatalf("Tag tokens mismatch, got %v", feature.TagTokens)
+	}
+
+	background := feature.Background
+	if background.Keyword != "Background" || background.LineNumber != 4 || background.Column != 2 {
+		t.Fatalf("Background mismatch, got %+v", background)
+	}
+	step := background.Steps[0]
+	if step.Text != `a step with 2 "values"` {
+		t.Fatalf("Step text mismatch, expected %q, got %q", `a step with 2 "values"`, step.Text)
+	}
+	if step.DocString == nil || step.DocString.Content != "doc string" || step.DocString.LineNumber != 6 || step.DocString.Column != 4 {
+		t.Fatalf("DocString mismatch, got %+v", step.DocString)
+	}
+
+	outline := feature.Scenarios[0].(*object.ScenarioOutline)
+	if outline.Keyword != "Scenario Outline" {
+		t.Fatalf("Keyword mismatch, expected %q, got %q", "Scenario Outline", outline.Keyword)
+	}
+	if outline.ScenarioText != "outline <value>" {
+		t.Fatalf("Title mismatch, expected %q, got %q", "outline <value>", outline.ScenarioText)
+	}
+	if len(outline.TableTokens) != 1 || outline.TableTokens[0].LineNumber != 14 {
+		t.Fatalf("Table tokens mismatch, got %v", outline.TableTokens)
+	}
+	if len(outline.TableTagTokens) != 1 || len(outline.TableTagTokens[0]) != 1 || outline.TableTagTokens[0][0].LineNumber != 13 {
+		t.Fatalf("Table tag tokens mismatch, got %v", outline.TableTagTokens)
+	}
+}
+
+func TestParsingFromReader(t *testing.T) {
+	inputs := []string{`# language: en
+@coolTag
+Feature: test
+	a description
+	  over two lines
+
+	Background:
+		When I run background
+			| also | with |
+
+	Scenario: example scenario
+		When I do something "with" 2 strings
+		"""
+		doc
+		  spanning
+		lines
+		"""
+
+	Rule: a rule
+	Scenario Outline: another example scenario
+		When i do something <task>
+
+		@x
+		Examples:
+			| task |
+			| good |
+`}
+	for _, path := range []string{"../examples/test.feature", "../formatter/testdata/rules.feature", "../formatter/testdata/unicode.feature", "../formatter/testdata/docstrings.feature"} {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(src))
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		mutated := inputs[0]
+		pos := r.Intn(len(mutated) + 1)
+		mutated = mutated[:pos] + []string{"\n", "\"\"\"", "|", "Scenario:"}[r.Intn(4)] + mutated[pos:]
+		inputs = append(inputs, mutated)
+	}
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		expected := p.Parse()
+		l, err := lexer.NewFromReader(iotest.OneByteReader(strings.NewReader(input)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fromReader := New(l)
+		actual := fromReader.Parse()
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("FeatureSet mismatch for %q, expected %+v, got %+v", input, expected, actual)
+		}
+		if !reflect.DeepEqual(fromReader.getParserErrors(), p.getParserErrors()) {
+			t.Fatalf("Errors mismatch for %q, expected %v, got %v", input, p.getParserErrors(), fromReader.getParserErrors())
+		}
+	}
+}
+
+// benchFeature returns a feature with given number of scenarios and scenario
+// outlines, each outline with ten example rows
+func benchFeature(scenarios int) string {
+	var b strings.Builder
+	b.WriteString("@feature\nFeature: benchmark\n  Background:\n    Given a background step\n\n")
+	for i := 0; i < scenarios; i++ {
+		fmt.Fprintf(&b, "  @scenario\n  Scenario: scenario %d\n    Given a step with 2 \"strings\"\n      | a | b |\n\n", i)
+		fmt.Fprintf(&b, "  @outline\n  Scenario Outline: outline %d <name>\n    Given I eat <count> <name>\n", i)
+		b.WriteString("    When the table is\n      | <name> | <count> |\n    Then the DocString is\n      \"\"\"\n      <name> was eaten\n      \"\"\"\n\n")
+		b.WriteString("    @examples\n    Examples:\n      | name | count |\n")
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&b, "      | fruit %d | %d |\n", j, j)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, scenarios := range []int{10, 100, 1000} {
+		input := benchFeature(scenarios)
+		b.Run(fmt.Sprintf("scenarios=%d", scenarios), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				p := New(lexer.New(input))
+				if p.Parse() == nil {
+					b.Fatal(p.getParserErrors())
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseSources(b *testing.B) {
+	var sources []Source
+	for i := 0; i < 100; i++ {
+		sources = append(sources, Source{Path: fmt.Sprintf("%03d.feature", i), Content: []byte(benchFeature(10))})
+	}
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseSources(context.Background(), sources, &ParseOptions{Workers: workers}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Package pickles compiles features into pickles, the flat list of
+// executable scenarios
+//
+// Every scenario becomes a pickle and every example row of a scenario
+// outline becomes a pickle of its own. The background steps are prepended to
+// the steps of the pickle, the outline placeholders are substituted and the
+// tags of the feature, rule, scenario and examples are merged.
+package pickles
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/token"
+)
+
+// Pickle is a compiled scenario ready to be executed
+//
+// AstNodeIds point back to the scenario and, for scenario outlines, the
+// example row the pickle was compiled from
+type Pickle struct {
+	ID         string
+	URI        string
+	Name       string
+	Language   string
+	Steps      []Step
+	Tags       []Tag
+	AstNodeIds []string
+
+	// LineNumber and Column are the location of the scenario, ExampleLineNumber
+	// is the line of the example row for the pickles of scenario outlines
+	LineNumber        int
+	Column            int
+	ExampleLineNumber int
+}
+
+// Step is a step of a Pickle with the placeholders substituted
+//
+// Type is the type of the step, one of Context, Action, Outcome or Unknown,
+// And and But steps take the type of the step before them
+type Step struct {
+	ID         string
+	Keyword    string
+	Type       string
+	Text       string
+	Table      object.Table
+	DocString  *object.DocString
+	AstNodeIds []string
+	LineNumber int
+	Column     int
+}
+
+// Tag is a tag of a Pickle along with the ID of the tag it came from
+type Tag struct {
+	Name      string
+	AstNodeID string
+}
+
+// Compiler compiles features into pickles
+type Compiler struct {
+	// NewID returns the IDs of the pickles and their steps, by default the
+	// IDs are incrementing numbers
+	NewID func() string
+	// NodeID returns the ID of the AST node at given location, by default
+	// the IDs are "line:column"
+	NodeID func(line, column int) string
+}
+
+// Compile compiles the feature into pickles with the default Compiler, the
+// features of a FeatureSet are compiled with CompileFeatureSet so the IDs are
+// not repeated
+func Compile(feature *object.Feature) ([]Pickle, error) {
+	return (&Compiler{}).Compile(feature)
+}
+
+// CompileFeatureSet compiles all the features of the FeatureSet into pickles
+// with the default Compiler
+func CompileFeatureSet(featureSet *object.FeatureSet) ([]Pickle, error) {
+	return (&Compiler{}).CompileFeatureSet(featureSet)
+}
+
+// CompileFeatureSet compiles the features one after the other, the IDs are
+// unique across all the features and the error is the first one of any
+// feature
+func (c *Compiler) CompileFeatureSet(featureSet *object.FeatureSet) ([]Pickle, error) {
+	var pickles []Pickle
+	var firstErr error
+	for i := range featureSet.Features {
+		compiled, err := c.Compile(&featureSet.Features[i])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		pickles = append(pickles, compiled...)
+	}
+	return pickles, firstErr
+}
+
+// Compile compiles the scenarios of the feature and of its rules into pickles,
+// the IDs keep counting across the calls on the same Compiler
+//
+// An *object.PlaceholderError with the URI of the feature is returned for the
+// first placeholder that is not a column of the examples, the pickles are
+// still compiled with such placeholders kept as they are. The same goes for
+// an *object.ExamplesRowError and the example rows it leaves out.
+func (c *Compiler) Compile(feature *object.Feature) ([]Pickle, error) {
+	if c.NewID == nil {
+		next := 0
+		c.NewID = func() string {
+			id := strconv.Itoa(next)
+			next++
+			return id
+		}
+	}
+	if c.NodeID == nil {
+		c.NodeID = func(line, column int) string {
+			return fmt.Sprintf("%d:%d", line, column)
+		}
+	}
+
+	var pickles []Pickle
+	var firstErr error
+	compile := func(tags []Tag, background []object.Step, scenario object.ScenarioType) {
+		compiled, err := c.compileScenarioType(feature, tags, background, scenario)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		pickles = append(pickles, compiled...)
+	}
+
+	tags := c.tags(feature.TagTokens, feature.Tags)
+	background := backgroundSteps(feature.Background)
+	for _, scenario := range feature.Scenarios {
+		compile(tags, background, scenario)
+	}
+	for _, rule := range feature.Rules {
+		ruleTags := append(append([]Tag{}, tags...), c.tags(rule.TagTokens, rule.Tags)...)
+		ruleBackground := append(append([]object.Step{}, background...), backgroundSteps(rule.Background)...)
+		for _, scenario := range rule.Scenarios {
+			compile(ruleTags, ruleBackground, scenario)
+		}
+	}
+	return pickles, firstErr
+}
+
+func backgroundSteps(background *object.Background) []object.Step {
+	if background == nil {
+		return nil
+	}
+	return background.Steps
+}
+
+func (c *Compiler) compileScenarioType(feature *object.Feature, tags []Tag, background []object.Step, scenarioType object.ScenarioType) ([]Pickle, error) {
+	switch scenario := scenarioType.(type) {
+	case *object.Scenario:
+		pickle := Pickle{
+			URI:        feature.URI,
+			Name:       scenario.ScenarioText,
+			Language:   feature.Language,
+			Tags:       append(append([]Tag{}, tags...), c.tags(scenario.TagTokens, scenario.Tags)...),
+			AstNodeIds: []string{c.NodeID(scenario.LineNumber, scenario.Column)},
+			LineNumber: scenario.LineNumber,
+			Column:     scenario.Column,
+		}
+		pickle.Steps = c.steps(background, scenario.Steps, "")
+		pickle.ID = c.NewID()
+		return []Pickle{pickle}, nil
+	case *object.ScenarioOutline:
+		return c.compileOutline(feature, tags, background, scenario)
+	}
+	return nil, nil
+}
+
+// compileOutline compiles the scenarios expanded from the example rows of
+// the outline, the expanded scenarios are on the lines of their rows
+func (c *Compiler) compileOutline(feature *object.Feature, tags []Tag, background []object.Step, outline *object.ScenarioOutline) ([]Pickle, error) {
+	scenarios, err := outline.Expand()
+	switch err := err.(type) {
+	case *object.PlaceholderError:
+		err.URI = feature.URI
+	case *object.ExamplesRowError:
+		err.URI = feature.URI
+	}
+
+	scenarioTags := append(append([]Tag{}, tags...), c.tags(outline.TagTokens, outline.Tags)...)
+	exampleTags := map[int][]Tag{}
+	for i, table := range outline.Tables {
+		if len(table) < 2 {
+			continue
+		}
+		var tableTagTokens []token.Token
+		var tableTags []string
+		if i < len(outline.TableTagTokens) {
+			tableTagTokens = outline.TableTagTokens[i]
+		}
+		if i < len(outline.TableTags) {
+			tableTags = outline.TableTags[i]
+		}
+		rowTags := append(append([]Tag{}, scenarioTags...), c.tags(tableTagTokens, tableTags)...)
+		for _, row := range table[1:] {
+			if len(row) != 0 {
+				exampleTags[row[0].LineNumber] = rowTags
+			}
+		}
+	}
+
+	var pickles []Pickle
+	for _, scenario := range scenarios {
+		exampleID := c.NodeID(scenario.LineNumber, scenario.Column)
+		pickle := Pickle{
+			URI:               feature.URI,
+			Name:              scenario.ScenarioText,
+			Language:          feature.Language,
+			Tags:              exampleTags[scenario.LineNumber],
+			AstNodeIds:        []string{c.NodeID(outline.LineNumber, outline.Column), exampleID},
+			LineNumber:        outline.LineNumber,
+			Column:            outline.Column,
+			ExampleLineNumber: scenario.LineNumber,
+		}
+		pickle.Steps = c.steps(background, scenario.Steps, exampleID)
+		pickle.ID = c.NewID()
+		pickles = append(pickles, pickle)
+	}
+	return pickles, err
+}
+
+// steps compiles the background steps followed by the scenario steps, the
+// scenario steps point back to the example row too when there is one
+func (c *Compiler) steps(background, steps []object.Step, exampleID string) []Step {
+	res := []Step{}
+	lastType := "Unknown"
+	compile := func(step object.Step, astNodeIds []string) {
+		stepType := stepType(step.Token.Type)
+		if stepType == "" {
+			stepType = lastType
+		}
+		lastType = stepType
+		res = append(res, Step{
+			Keyword:    step.Token.Literal,
+			Type:       stepType,
+			Text:       step.Text,
+			Table:      step.Table,
+			DocString:  step.DocString,
+			AstNodeIds: astNodeIds,
+			LineNumber: step.LineNumber,
+			Column:     step.Column,
+			ID:         c.NewID(),
+		})
+	}
+
+	for _, step := range background {
+		compile(step, []string{c.NodeID(step.LineNumber, step.Column)})
+	}
+	for _, step := range steps {
+		astNodeIds := []string{c.NodeID(step.LineNumber, step.Column)}
+		if exampleID != "" {
+			astNodeIds = append(astNodeIds, exampleID)
+		}
+		compile(step, astNodeIds)
+	}
+	return res
+}
+
+// stepType returns the type of the step keyword, And and But steps have no
+// type of their own
+func stepType(t token.Type) string {
+	switch t {
+	case token.GIVEN:
+		return "Context"
+	case token.WHEN:
+		return "Action"
+	case token.THEN:
+		return "Outcome"
+	}
+	return ""
+}
+
+// tags converts the tag tokens into pickle tags, when the tokens are missing
+// the tag names are used with the IDs of an unknown location
+func (c *Compiler) tags(tokens []token.Token, names []string) []Tag {
+	tags := []Tag{}
+	if len(tokens) != len(names) {
+		for _, name := range names {
+			tags = append(tags, Tag{Name: name, AstNodeID: c.NodeID(0, 0)})
+		}
+		return tags
+	}
+	for _, tag := range tokens {
+		tags = append(tags, Tag{Name: tag.Literal, AstNodeID: c.NodeID(tag.LineNumber, tag.Column)})
+	}
+	return tags
+}
@@ -0,0 +1,362 @@
+package pickles
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/lexer"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+func parseFeature(t testing.TB, input string) *object.Feature {
+	l := lexer.New(input)
+	l.FilePath = "pickles.feature"
+	p := parser.New(l)
+	featureSet := p.Parse()
+	for _, err := range p.Errors() {
+		t.Error(err.GetMessage())
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+	return &featureSet.Features[0]
+}
+
+func areArrayEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCompile(t *testing.T) {
+	input := `@feature
+Feature: pickles
+	Background:
+		Given a background step
+
+	@scenario
+	Scenario: plain scenario
+		When I do something
+		And I do something else
+		Then it works
+
+	@outline
+	Scenario Outline: eat <count> <food>
+		When I eat <count> <food>
+			| food   | count   |
+			| <food> | <count> |
+		Then I have eaten
+			"""
+			<count> <food>
+			"""
+
+		@first
+		Examples:
+			| count | food    |
+			| 2     | apples  |
+
+		@second
+		Examples:
+			| count | food    |
+			| 3     | bananas |
+			| 4     | <none>  |
+
+	@rule
+	Rule: a rule
+		Background:
+			Given a rule background step
+
+		Scenario: rule scenario
+			* it passes
+`
+	pickles, err := Compile(parseFeature(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name       string
+		steps      []string
+		types      []string
+		tags       []string
+		astNodeIds []string
+		line       int
+		exampleLn  int
+	}{
+		{
+			"plain scenario",
+			[]string{"a background step", "I do something", "I do something else", "it works"},
+			[]string{"Context", "Action", "Action", "Outcome"},
+			[]string{"feature", "scenario"},
+			[]string{"7:2"},
+			7, 0,
+		},
+		{
+			"eat 2 apples",
+			[]string{"a background step", "I eat 2 apples", "I have eaten"},
+			[]string{"Context", "Action", "Outcome"},
+			[]string{"feature", "outline", "first"},
+			[]string{"13:2", "25:6"},
+			13, 25,
+		},
+		{
+			"eat 3 bananas",
+			[]string{"a background step", "I eat 3 bananas", "I have eaten"},
+			[]string{"Context", "Action", "Outcome"},
+			[]string{"feature", "outline", "second"},
+			[]string{"13:2", "30:6"},
+			13, 30,
+		},
+		{
+			"eat 4 <none>",
+			[]string{"a background step", "I eat 4 <none>", "I have eaten"},
+			[]string{"Context", "Action", "Outcome"},
+			[]string{"feature", "outline", "second"},
+			[]string{"13:2", "31:6"},
+			13, 31,
+		},
+		{
+			"rule scenario",
+			[]string{"a background step", "a rule background step", "it passes"},
+			[]string{"Context", "Context", "Context"},
+			[]string{"feature", "rule"},
+			[]string{"38:3"},
+			38, 0,
+		},
+	}
+
+	if len(pickles) != len(expected) {
+		t.Fatalf("Pickle count mismatch, expected %v, got %v", len(expected), len(pickles))
+	}
+	ids := map[string]bool{}
+	for i, tt := range expected {
+		pickle := pickles[i]
+		if pickle.Name != tt.name {
+			t.Fatalf("Name mismatch, expected %q, got %q", tt.name, pickle.Name)
+		}
+		if pickle.URI != "pickles.feature" || pickle.Language != "en" {
+			t.Fatalf("Pickle mismatch, got %+v", pickle)
+		}
+		if pickle.LineNumber != tt.line || pickle.ExampleLineNumber != tt.exampleLn {
+			t.Fatalf("Location mismatch, expected %v and %v, got %v and %v", tt.line, tt.exampleLn, pickle.LineNumber, pickle.ExampleLineNumber)
+		}
+		var steps, types []string
+		for _, step := range pickle.Steps {
+			steps = append(steps, step.Text)
+			types = append(types, step.Type)
+			if ids[step.ID] {
+				t.Fatalf("Duplicate id %q", step.ID)
+			}
+			ids[step.ID] = true
+		}
+		if !areArrayEqual(steps, tt.steps) {
+			t.Fatalf("Steps mismatch, expected %q, got %q", tt.steps, steps)
+		}
+		if !areArrayEqual(types, tt.types) {
+			t.Fatalf("Step types mismatch, expected %q, got %q", tt.types, types)
+		}
+		var tags []string
+		for _, tag := range pickle.Tags {
+			tags = append(tags, tag.Name)
+		}
+		if !areArrayEqual(tags, tt.tags) {
+			t.Fatalf("Tags mismatch, expected %q, got %q", tt.tags, tags)
+		}
+		if !areArrayEqual(pickle.AstNodeIds, tt.astNodeIds) {
+			t.Fatalf("AstNodeIds mismatch, expected %q, got %q", tt.astNodeIds, pickle.AstNodeIds)
+		}
+		if ids[pickle.ID] {
+			t.Fatalf("Duplicate id %q", pickle.ID)
+		}
+		ids[pickle.ID] = true
+	}
+
+	outlineStep := pickles[1].Steps[1]
+	if !areArrayEqual(outlineStep.AstNodeIds, []string{"14:3", "25:6"}) {
+		t.Fatalf("Step AstNodeIds mismatch, got %q", outlineStep.AstNodeIds)
+	}
+	if !areArrayEqual(pickles[1].Steps[0].AstNodeIds, []string{"4:3"}) {
+		t.Fatalf("Background step AstNodeIds mismatch, got %q", pickles[1].Steps[0].AstNodeIds)
+	}
+	if cell := outlineStep.Table[1][0]; cell.Literal != "apples" || cell.LineNumber != 16 {
+		t.Fatalf("Table cell mismatch, got %+v", cell)
+	}
+	if outlineStep.Table[0][0].Literal != "food" {
+		t.Fatalf("Table header mismatch, got %+v", outlineStep.Table[0][0])
+	}
+	if docString := pickles[2].Steps[2].DocString; docString == nil || docString.Content != "3 bananas" {
+		t.Fatalf("DocString mismatch, got %+v", docString)
+	}
+	if pickles[0].Tags[1].AstNodeID != "6:2" {
+		t.Fatalf("Tag AstNodeID mismatch, expected %q, got %q", "6:2", pickles[0].Tags[1].AstNodeID)
+	}
+}
+
+func TestCompileLeavesFeatureUnchanged(t *testing.T) {
+	input := `Feature: unchanged
+	Scenario Outline: outline <value>
+		Given a step <value>
+			"""
+			<value>
+			"""
+
+		Examples:
+			| value |
+			| 1     |
+`
+	feature := parseFeature(t, input)
+	if _, err := Compile(feature); err != nil {
+		t.Fatal(err)
+	}
+
+	outline := feature.Scenarios[0].(*object.ScenarioOutline)
+	if outline.ScenarioText != "outline <value>" {
+		t.Fatalf("Title mismatch, expected %q, got %q", "outline <value>", outline.ScenarioText)
+	}
+	if outline.Steps[0].Text != "a step <value>" || outline.Steps[0].DocString.Content != "<value>" {
+		t.Fatalf("Step mismatch, got %+v", outline.Steps[0])
+	}
+}
+
+func TestCompileFeatureSetIDs(t *testing.T) {
+	input := `Feature: ids
+	Scenario: one
+		Given a step
+		When another step
+`
+	featureSet := &object.FeatureSet{Features: []object.Feature{*parseFeature(t, input), *parseFeature(t, input)}}
+
+	// The IDs of the pickles and their steps are not repeated across features
+	seen := map[string]bool{}
+	compiled, err := CompileFeatureSet(featureSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compiled) != 2 {
+		t.Fatalf("Pickles length mismatch, expected 2, got %v", len(compiled))
+	}
+	for _, pickle := range compiled {
+		ids := []string{pickle.ID}
+		for _, step := range pickle.Steps {
+			ids = append(ids, step.ID)
+		}
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("Expected unique IDs but %q is repeated", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestCompileUndefinedPlaceholder(t *testing.T) {
+	input := `Feature: placeholders
+	Scenario Outline: eat <food>
+		When I eat <count> <food>
+
+		Examples:
+			| food   |
+			| apples |
+`
+	compiled, err := Compile(parseFeature(t, input))
+
+	placeholderErr, ok := err.(*object.PlaceholderError)
+	if !ok {
+		t.Fatalf("Expected a *object.PlaceholderError but got %v", err)
+	}
+	expected := "pickles.feature:3:3: placeholder <count> is not a column of the examples on line 6"
+	if placeholderErr.Error() != expected {
+		t.Fatalf("Error mismatch, expected %q, got %q", expected, placeholderErr.Error())
+	}
+
+	// The pickles are still compiled with the placeholder kept
+	if len(compiled) != 1 || compiled[0].Steps[0].Text != "I eat <count> apples" {
+		t.Fatalf("Pickles mismatch, got %+v", compiled)
+	}
+}
+
+func TestCompileExamplesRowCells(t *testing.T) {
+	input := `Feature: rows
+	Scenario Outline: eat <food>
+		When I eat <food>
+
+		Examples:
+			| food   | count |
+			| apples |
+			| pears  | 2     |
+`
+	compiled, err := Compile(parseFeature(t, input))
+
+	expected := "pickles.feature:7:6: example row has 1 cells, expected 2"
+	if _, ok := err.(*object.ExamplesRowError); !ok || err.Error() != expected {
+		t.Fatalf("Error mismatch, expected %q, got %v", expected, err)
+	}
+	if len(compiled) != 1 || compiled[0].Name != "eat pears" {
+		t.Fatalf("Pickles mismatch, got %+v", compiled)
+	}
+}
+
+func TestCompileLiteralDataMarkers(t *testing.T) {
+	input := `Feature: markers
+	Scenario Outline: o
+		When i do someth{{d}}ing <task>
+		And I have 2 {{s}} "<task>" <task> items
+
+		Examples:
+			| task |
+			| 1    |
+`
+	compiled, err := Compile(parseFeature(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"i do someth{{d}}ing 1", `I have 2 {{s}} "1" 1 items`}
+	for i, step := range compiled[0].Steps {
+		if step.Text != expected[i] {
+			t.Fatalf("Text mismatch, expected %q, got %q", expected[i], step.Text)
+		}
+	}
+
+	// The numbers of the values are put in the Data in the order of the text
+	outline := parseFeature(t, input).Scenarios[0].(*object.ScenarioOutline)
+	scenarios, _ := outline.Expand()
+	step := scenarios[0].Steps[1]
+	if step.StepText != "I have {{d}} {{s}} {{s}} {{d}} items" || !areArrayEqual(step.Data, []string{"2", "1", "1"}) {
+		t.Fatalf("Step mismatch, got %q with %q", step.StepText, step.Data)
+	}
+}
+
+// benchFeature returns a feature with given number of scenario outlines, each
+// with placeholders in the steps, a table and a DocString and ten example rows
+func benchFeature(outlines int) string {
+	var b strings.Builder
+	b.WriteString("@feature\nFeature: benchmark\n  Background:\n    Given a background step\n\n")
+	for i := 0; i < outlines; i++ {
+		fmt.Fprintf(&b, "  @outline\n  Scenario Outline: outline %d <name>\n    Given I eat <count> <name>\n", i)
+		b.WriteString("    When the table is\n      | <name> | <count> |\n      | a | b |\n    Then the DocString is\n      \"\"\"\n      <name> was eaten\n      \"\"\"\n\n")
+		b.WriteString("    @examples\n    Examples:\n      | name | count |\n")
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&b, "      | fruit %d | %d |\n", j, j)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func BenchmarkCompile(b *testing.B) {
+	feature := parseFeature(b, benchFeature(100))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile(feature); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+func init() {
+	Register("json", NewJSONReporter)
+}
+
+// jsonDocument is the FeatureSet document of the object package with the
+// errors of the files that failed to parse
+type jsonDocument struct {
+	SchemaVersion string           `json:"schemaVersion"`
+	Features      []object.Feature `json:"features"`
+	Errors        []jsonError      `json:"errors,omitempty"`
+}
+
+type jsonError struct {
+	URI      string        `json:"uri"`
+	Message  string        `json:"message"`
+	Location *jsonLocation `json:"location,omitempty"`
+}
+
+type jsonLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// jsonReporter writes the features as a single JSON document once all of
+// them are reported
+type jsonReporter struct {
+	out io.Writer
+	doc jsonDocument
+}
+
+// NewJSONReporter creates the Reporter of the json format, the document is
+// described by the JSON Schema of object.SchemaVersion and can be read back
+// with object.UnmarshalFeatureSet
+func NewJSONReporter(out io.Writer) Reporter {
+	return &jsonReporter{out: out}
+}
+
+func (r *jsonReporter) Start() error {
+	r.doc = jsonDocument{SchemaVersion: object.SchemaVersion, Features: []object.Feature{}}
+	return nil
+}
+
+func (r *jsonReporter) Feature(feature *object.Feature) error {
+	r.doc.Features = append(r.doc.Features, *feature)
+	return nil
+}
+
+func (r *jsonReporter) Error(err *parser.FileError) error {
+	if err.Err != nil {
+		r.doc.Errors = append(r.doc.Errors, jsonError{URI: err.Path, Message: err.Err.Error()})
+		return nil
+	}
+	for _, parsingErr := range err.ParsingErrors {
+		line, column := parsingErr.Position()
+		r.doc.Errors = append(r.doc.Errors, jsonError{
+			URI:      err.Path,
+			Message:  parsingErr.Reason(),
+			Location: &jsonLocation{Line: line, Column: column},
+		})
+	}
+	return nil
+}
+
+func (r *jsonReporter) Finish() error {
+	encoder := json.NewEncoder(r.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.doc)
+}
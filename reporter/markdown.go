@@ -0,0 +1,327 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpakach/gorkin/formatter"
+	"github.com/dpakach/gorkin/internal/paths"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+func init() {
+	Register("markdown", NewMarkdownReporter)
+	RegisterDir("markdown", NewMarkdownDirReporter)
+}
+
+// markdownReporter writes the features as GitHub Flavored Markdown, into a
+// single output or into a file for every feature
+type markdownReporter struct {
+	out     io.Writer
+	written int
+
+	// dir is the directory of the files, the features are kept until Finish
+	// to find the directory shared by all of them
+	dir      string
+	features []*object.Feature
+}
+
+// NewMarkdownReporter creates the Reporter of the markdown format writing
+// all the features one after the other, the errors are not written
+func NewMarkdownReporter(out io.Writer) Reporter {
+	return &markdownReporter{out: out}
+}
+
+// NewMarkdownDirReporter creates the Reporter of the markdown format writing
+// every feature to its own .md file in the directory, the files mirror the
+// directories of the feature files below the directory they all share
+func NewMarkdownDirReporter(dir string) Reporter {
+	return &markdownReporter{dir: dir}
+}
+
+func (r *markdownReporter) Start() error {
+	r.written, r.features = 0, nil
+	return nil
+}
+
+func (r *markdownReporter) Feature(feature *object.Feature) error {
+	if r.dir != "" {
+		r.features = append(r.features, feature)
+		return nil
+	}
+	var b bytes.Buffer
+	if r.written != 0 {
+		b.WriteString("\n")
+	}
+	r.written++
+	writeMarkdown(&b, feature)
+	_, err := r.out.Write(b.Bytes())
+	return err
+}
+
+func (r *markdownReporter) Error(err *parser.FileError) error {
+	return nil
+}
+
+func (r *markdownReporter) Finish() error {
+	if r.dir == "" {
+		return nil
+	}
+	uris := make([]string, len(r.features))
+	for i, feature := range r.features {
+		uris[i] = filepath.ToSlash(feature.URI)
+	}
+	root := paths.CommonDir(uris)
+	taken := map[string]bool{}
+	for i, feature := range r.features {
+		var b bytes.Buffer
+		writeMarkdown(&b, feature)
+		name := strings.TrimPrefix(strings.TrimPrefix(uris[i], root), "/")
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`<>:"|?*`, r) {
+				return '_'
+			}
+			return r
+		}, strings.TrimSuffix(name, path.Ext(name)))
+		// The files outside of the common directory keep their ../ which
+		// would write them outside of the directory
+		name = uniqueName(strings.Replace(name, "..", "__", -1), taken)
+		target := filepath.Join(r.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, b.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueName returns the name with the .md extension, numbered when the name
+// is taken already
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name + ".md"
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%v-%v.md", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// writeMarkdown writes the feature with the feature as the first heading and
+// the rules, backgrounds, scenarios and examples below it
+func writeMarkdown(b *bytes.Buffer, feature *object.Feature) {
+	markdownHeading(b, 1, orKeyword(feature.Token.Literal, "Feature"), feature.Title)
+	markdownTags(b, feature.Tags)
+	markdownDescription(b, feature.Description)
+	if feature.Background != nil {
+		markdownBackground(b, 2, feature.Background)
+	}
+	markdownScenarios(b, 2, feature.Scenarios)
+	for _, rule := range feature.Rules {
+		markdownHeading(b, 2, orKeyword(rule.Token.Literal, "Rule"), rule.Title)
+		markdownTags(b, rule.Tags)
+		markdownDescription(b, rule.Description)
+		if rule.Background != nil {
+			markdownBackground(b, 3, rule.Background)
+		}
+		markdownScenarios(b, 3, rule.Scenarios)
+	}
+}
+
+func markdownHeading(b *bytes.Buffer, level int, keyword, title string) {
+	b.WriteString(strings.Repeat("#", level) + " " + keyword + ":")
+	if title != "" {
+		b.WriteString(" " + markdownEscape(title))
+	}
+	b.WriteString("\n\n")
+}
+
+// markdownTags writes the tags as badges, code spans are shown as such by
+// every Markdown renderer
+func markdownTags(b *bytes.Buffer, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	for i, tag := range tags {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("`@" + tag + "`")
+	}
+	b.WriteString("\n\n")
+}
+
+// markdownDescription writes the description as it is, the descriptions are
+// often written in Markdown already
+func markdownDescription(b *bytes.Buffer, description string) {
+	lines := formatter.DescriptionLines(strings.Trim(description, "\n"))
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString(strings.Join(lines, "\n") + "\n\n")
+}
+
+func markdownBackground(b *bytes.Buffer, level int, background *object.Background) {
+	markdownHeading(b, level, orKeyword(background.Keyword, "Background"), background.Title)
+	markdownSteps(b, background.Steps)
+}
+
+func markdownScenarios(b *bytes.Buffer, level int, scenarioTypes []object.ScenarioType) {
+	for _, scenarioType := range scenarioTypes {
+		switch scenario := scenarioType.(type) {
+		case *object.Scenario:
+			markdownHeading(b, level, orKeyword(scenario.Keyword, "Scenario"), scenario.ScenarioText)
+			markdownTags(b, scenario.Tags)
+			markdownDescription(b, scenario.Description)
+			markdownSteps(b, scenario.Steps)
+		case *object.ScenarioOutline:
+			markdownHeading(b, level, orKeyword(scenario.Keyword, "Scenario Outline"), scenario.ScenarioText)
+			markdownTags(b, scenario.Tags)
+			markdownDescription(b, scenario.Description)
+			markdownSteps(b, scenario.Steps)
+			for i, table := range scenario.Tables {
+				keyword := "Examples"
+				if i < len(scenario.TableTokens) {
+					keyword = scenario.TableTokens[i].Literal
+				}
+				name := ""
+				if i < len(scenario.TableNames) {
+					name = scenario.TableNames[i]
+				}
+				markdownHeading(b, level+1, keyword, name)
+				if i < len(scenario.TableTags) {
+					markdownTags(b, scenario.TableTags[i])
+				}
+				if i < len(scenario.TableDescriptions) {
+					markdownDescription(b, scenario.TableDescriptions[i])
+				}
+				markdownTable(b, "", table)
+				b.WriteString("\n")
+			}
+		}
+	}
+}
+
+// markdownSteps writes the steps as a list, the tables and the DocStrings
+// are indented to stay in the list item of their step
+func markdownSteps(b *bytes.Buffer, steps []object.Step) {
+	if len(steps) == 0 {
+		return
+	}
+	// A blank line after a table or a DocString keeps the next step out of it
+	blank := false
+	for _, step := range steps {
+		b.WriteString("- **" + markdownEscape(step.Token.Literal) + "** " + markdownEscape(step.Text) + "\n")
+		blank = false
+		if len(step.Table) != 0 {
+			b.WriteString("\n")
+			markdownTable(b, "  ", step.Table)
+			b.WriteString("\n")
+			blank = true
+		}
+		if step.DocString != nil {
+			b.WriteString("\n")
+			markdownDocString(b, "  ", step.DocString)
+			b.WriteString("\n")
+			blank = true
+		}
+	}
+	if !blank {
+		b.WriteString("\n")
+	}
+}
+
+// markdownTable writes the table with the columns padded to the widest cell,
+// the first row is the header like in the Examples
+func markdownTable(b *bytes.Buffer, indent string, table object.Table) {
+	var widths []int
+	var rows [][]string
+	for _, row := range table {
+		var cells []string
+		for i, cell := range row {
+			text := strings.Replace(markdownEscape(cell.Literal), "|", `\|`, -1)
+			if i >= len(widths) {
+				widths = append(widths, 3)
+			}
+			if width := formatter.DisplayWidth(text); width > widths[i] {
+				widths[i] = width
+			}
+			cells = append(cells, text)
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	writeRow := func(cells []string) {
+		b.WriteString(indent + "|")
+		for i, width := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			b.WriteString(" " + cell + strings.Repeat(" ", width-formatter.DisplayWidth(cell)) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rows[0])
+	b.WriteString(indent + "|")
+	for _, width := range widths {
+		b.WriteString(" " + strings.Repeat("-", width) + " |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+}
+
+// markdownDocString writes the DocString as a fenced code block with the media
+// type as its info string, the fence is longer than any run of backticks in
+// the content
+func markdownDocString(b *bytes.Buffer, indent string, docString *object.DocString) {
+	lines := formatter.DocStringLines(docString)
+	fence := "```"
+	for _, line := range lines {
+		for strings.Contains(line, fence) {
+			fence += "`"
+		}
+	}
+	b.WriteString(indent + fence + docString.MediaType + "\n")
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + fence + "\n")
+}
+
+// markdownEscape escapes the characters that would be read as Markdown or
+// HTML in the text
+func markdownEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<>#", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func orKeyword(keyword, defaultKeyword string) string {
+	if keyword == "" {
+		return defaultKeyword
+	}
+	return keyword
+}
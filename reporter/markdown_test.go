@@ -0,0 +1,159 @@
+package reporter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+const markdownInput = `@shop @cart
+Feature: Cart *totals*
+	Adding <things> to the cart
+
+	Background:
+		Given an empty cart
+
+	Scenario: add one
+		When I add 1 "apple"
+		Then the cart has:
+			| item  | price |
+			| apple | 1_2   |
+		And the receipt is:
+			"""
+			` + "```" + `
+			total 1
+			` + "```" + `
+			"""
+
+	Rule: discounts
+		Scenario Outline: add <count> <item>
+			When I add <count> "<item>"
+
+			@fruit
+			Examples:
+				| count | item   |
+				| 2     | banana |
+`
+
+func TestMarkdownReporter(t *testing.T) {
+	var out bytes.Buffer
+	featureSet := parseFeatureSet(t, markdownInput)
+	featureSet.Features = append(featureSet.Features, featureSet.Features[0])
+	if err := Report([]Reporter{NewMarkdownReporter(&out)}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	expected := []string{
+		"# Feature: Cart \\*totals\\*\n\n`@shop` `@cart`\n\nAdding <things> to the cart\n\n",
+		"## Background:\n\n- **Given** an empty cart\n\n## Scenario: add one\n\n",
+		"- **Then** the cart has:\n\n" +
+			"  | item  | price |\n" +
+			"  | ----- | ----- |\n" +
+			"  | apple | 1\\_2  |\n\n",
+		"- **And** the receipt is:\n\n  ````\n  ```\n  total 1\n  ```\n  ````\n\n## Rule: discounts\n\n",
+		"### Scenario Outline: add \\<count\\> \\<item\\>\n\n",
+		"#### Examples:\n\n`@fruit`\n\n" +
+			"| count | item   |\n" +
+			"| ----- | ------ |\n" +
+			"| 2     | banana |\n\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(got, s) {
+			t.Fatalf("Expected the output to contain %q but got %v", s, got)
+		}
+	}
+	// The features are separated by a single blank line
+	if strings.Count(got, "# Feature:") != 2 || !strings.Contains(got, "| 2     | banana |\n\n\n# Feature:") {
+		t.Fatalf("Expected two features separated by a blank line but got %v", got)
+	}
+}
+
+func TestMarkdownDirReporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorkin-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	featureSet := &object.FeatureSet{}
+	for _, uri := range []string{"specs/cart.feature", "specs/shop/pay.feature", "specs/cart.txt"} {
+		fs, err := parser.ParseSource(uri, []byte("Feature: "+uri+"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		featureSet.Merge(fs)
+	}
+
+	r, err := NewDir("markdown", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Report([]Reporter{r}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"cart.md", "# Feature: specs/cart.feature\n\n"},
+		{"shop/pay.md", "# Feature: specs/shop/pay.feature\n\n"},
+		{"cart-2.md", "# Feature: specs/cart.txt\n\n"},
+	}
+	for _, tt := range tests {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != tt.expected {
+			t.Fatalf("Content mismatch of %v, expected %q, got %q", tt.name, tt.expected, content)
+		}
+	}
+
+	if _, err := NewDir("json", dir); err == nil {
+		t.Fatalf("Expected an error for a format not written to a directory")
+	}
+}
+
+func TestMarkdownDirReporterParentPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorkin-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	featureSet := &object.FeatureSet{}
+	for _, uri := range []string{"../other/a.feature", "b.feature"} {
+		fs, err := parser.ParseSource(uri, []byte("Feature: "+uri+"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		featureSet.Merge(fs)
+	}
+
+	out := filepath.Join(dir, "out")
+	r, err := NewDir("markdown", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Report([]Reporter{r}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The files are written inside of the directory only
+	for _, name := range []string{"__/other/a.md", "b.md"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no files outside of the directory but got %v", err)
+	}
+}
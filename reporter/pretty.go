@@ -0,0 +1,398 @@
+package reporter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dpakach/gorkin/formatter"
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+func init() {
+	Register("pretty", NewPrettyReporter)
+}
+
+// The ANSI escape sequences of the colours of the pretty format
+const (
+	colorReset       = "\x1b[0m"
+	colorKeyword     = "\x1b[1;34m"
+	colorTag         = "\x1b[36m"
+	colorString      = "\x1b[32m"
+	colorNumber      = "\x1b[33m"
+	colorPlaceholder = "\x1b[35m"
+	colorComment     = "\x1b[90m"
+)
+
+// prettyReporter writes the features as indented Gherkin for reading in a
+// terminal, with the location of every scenario next to it
+type prettyReporter struct {
+	out     io.Writer
+	color   bool
+	written int
+}
+
+// NewPrettyReporter creates the Reporter of the pretty format, the output is
+// coloured when it is a terminal and the NO_COLOR environment variable is not
+// set, the errors are not written
+func NewPrettyReporter(out io.Writer) Reporter {
+	return &prettyReporter{out: out, color: useColor(out)}
+}
+
+// useColor reports whether the colours can be written to the output
+func useColor(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r *prettyReporter) Start() error {
+	r.written = 0
+	return nil
+}
+
+func (r *prettyReporter) Feature(feature *object.Feature) error {
+	p := &prettyPrinter{color: r.color, uri: feature.URI}
+	p.feature(feature)
+	var b bytes.Buffer
+	if r.written != 0 {
+		b.WriteString("\n")
+	}
+	r.written++
+	p.writeTo(&b)
+	_, err := r.out.Write(b.Bytes())
+	return err
+}
+
+func (r *prettyReporter) Error(err *parser.FileError) error {
+	return nil
+}
+
+func (r *prettyReporter) Finish() error {
+	return nil
+}
+
+// prettyLine is a line of the output, width is the displayed width of the
+// text without the colours
+type prettyLine struct {
+	text     string
+	width    int
+	location string
+}
+
+// prettyPrinter collects the lines of a feature, the locations are aligned
+// in a column when the lines are written
+type prettyPrinter struct {
+	color bool
+	uri   string
+	lines []prettyLine
+}
+
+// styled is a line being built from pieces of text in their colours
+type styled struct {
+	p     *prettyPrinter
+	text  strings.Builder
+	width int
+}
+
+func (s *styled) add(color, text string) *styled {
+	if s.p.color && color != "" && text != "" {
+		s.text.WriteString(color + text + colorReset)
+	} else {
+		s.text.WriteString(text)
+	}
+	s.width += formatter.DisplayWidth(text)
+	return s
+}
+
+func (p *prettyPrinter) start(level int) *styled {
+	s := &styled{p: p}
+	return s.add("", strings.Repeat("  ", level))
+}
+
+// end adds the line, the location is written after it when it is not 0
+func (p *prettyPrinter) end(s *styled, lineNumber int) {
+	line := prettyLine{text: s.text.String(), width: s.width}
+	if lineNumber != 0 {
+		line.location = "# " + p.uri + ":" + strconv.Itoa(lineNumber)
+	}
+	p.lines = append(p.lines, line)
+}
+
+func (p *prettyPrinter) blankLine() {
+	if len(p.lines) > 0 {
+		p.lines = append(p.lines, prettyLine{})
+	}
+}
+
+func (p *prettyPrinter) writeTo(b *bytes.Buffer) {
+	column := 0
+	for _, line := range p.lines {
+		if line.location != "" && line.width > column {
+			column = line.width
+		}
+	}
+	for _, line := range p.lines {
+		b.WriteString(line.text)
+		if line.location != "" {
+			s := &styled{p: p}
+			s.add("", strings.Repeat(" ", column-line.width+2)).add(colorComment, line.location)
+			b.WriteString(s.text.String())
+		}
+		b.WriteString("\n")
+	}
+}
+
+func (p *prettyPrinter) feature(feature *object.Feature) {
+	p.tags(0, feature.Tags)
+	p.heading(0, orKeyword(feature.Token.Literal, "Feature"), feature.Title, feature.Token.LineNumber)
+	p.description(1, feature.Description)
+	if feature.Background != nil {
+		p.background(1, feature.Background)
+	}
+	p.scenarios(1, feature.Scenarios)
+	for _, rule := range feature.Rules {
+		p.blankLine()
+		p.tags(1, rule.Tags)
+		p.heading(1, orKeyword(rule.Token.Literal, "Rule"), rule.Title, rule.Token.LineNumber)
+		p.description(2, rule.Description)
+		if rule.Background != nil {
+			p.background(2, rule.Background)
+		}
+		p.scenarios(2, rule.Scenarios)
+	}
+}
+
+func (p *prettyPrinter) tags(level int, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	s := p.start(level)
+	for i, tag := range tags {
+		if i > 0 {
+			s.add("", " ")
+		}
+		s.add(colorTag, "@"+tag)
+	}
+	p.end(s, 0)
+}
+
+func (p *prettyPrinter) heading(level int, keyword, title string, lineNumber int) {
+	s := p.start(level).add(colorKeyword, keyword+":")
+	if title != "" {
+		s.add("", " ")
+		p.placeholders(s, "", title)
+	}
+	p.end(s, lineNumber)
+}
+
+func (p *prettyPrinter) description(level int, description string) {
+	for _, line := range formatter.DescriptionLines(strings.Trim(description, "\n")) {
+		p.end(p.start(level).add("", line), 0)
+	}
+}
+
+func (p *prettyPrinter) background(level int, background *object.Background) {
+	p.blankLine()
+	p.heading(level, orKeyword(background.Keyword, "Background"), background.Title, background.LineNumber)
+	p.steps(level+1, background.Steps)
+}
+
+func (p *prettyPrinter) scenarios(level int, scenarioTypes []object.ScenarioType) {
+	for _, scenarioType := range scenarioTypes {
+		p.blankLine()
+		switch scenario := scenarioType.(type) {
+		case *object.Scenario:
+			p.tags(level, scenario.Tags)
+			p.heading(level, orKeyword(scenario.Keyword, "Scenario"), scenario.ScenarioText, scenario.LineNumber)
+			p.description(level+1, scenario.Description)
+			p.steps(level+1, scenario.Steps)
+		case *object.ScenarioOutline:
+			p.tags(level, scenario.Tags)
+			p.heading(level, orKeyword(scenario.Keyword, "Scenario Outline"), scenario.ScenarioText, scenario.LineNumber)
+			p.description(level+1, scenario.Description)
+			p.steps(level+1, scenario.Steps)
+			for i, table := range scenario.Tables {
+				p.blankLine()
+				keyword := "Examples"
+				if i < len(scenario.TableTokens) {
+					keyword = scenario.TableTokens[i].Literal
+				}
+				if i < len(scenario.TableTags) {
+					p.tags(level+1, scenario.TableTags[i])
+				}
+				name := ""
+				if i < len(scenario.TableNames) {
+					name = scenario.TableNames[i]
+				}
+				p.heading(level+1, keyword, name, 0)
+				if i < len(scenario.TableDescriptions) {
+					p.description(level+2, scenario.TableDescriptions[i])
+				}
+				p.table(level+2, table)
+			}
+		}
+	}
+}
+
+func (p *prettyPrinter) steps(level int, steps []object.Step) {
+	for _, step := range steps {
+		s := p.start(level).add(colorKeyword, step.Token.Literal)
+		if step.Text != "" {
+			s.add("", " ")
+			p.stepText(s, &step)
+		}
+		p.end(s, 0)
+		p.table(level+1, step.Table)
+		if step.DocString != nil {
+			p.docString(level+1, step.DocString)
+		}
+	}
+}
+
+// stepText adds the text of the step with the data of the step highlighted,
+// the values of the Data are found in the text in the order of the {{d}} and
+// {{s}} placeholders of the StepText
+func (p *prettyPrinter) stepText(s *styled, step *object.Step) {
+	text := step.Text
+	rest := step.StepText
+	for _, data := range step.Data {
+		kind := dataPlaceholder(&rest)
+		if kind == "" {
+			break
+		}
+		needle := `"` + data + `"`
+		if kind == "{{d}}" {
+			needle = data
+		}
+		start := strings.Index(text, needle)
+		if start < 0 {
+			continue
+		}
+		end := start + len(needle)
+		p.placeholders(s, "", text[:start])
+		if kind == "{{d}}" && start > 0 && isWordByte(text[start-1]) {
+			// The digits at the end of a word are not shown as a number
+			s.add("", text[start:end])
+		} else if kind == "{{d}}" {
+			// The fraction is shown as a part of the number
+			for end+1 < len(text) && text[end] == '.' && isDigit(text[end+1]) {
+				end += 2
+				for end < len(text) && isDigit(text[end]) {
+					end++
+				}
+			}
+			s.add(colorNumber, text[start:end])
+		} else {
+			p.placeholders(s, colorString, text[start:end])
+		}
+		text = text[end:]
+	}
+	p.placeholders(s, "", text)
+}
+
+// dataPlaceholder returns the next {{d}} or {{s}} of the StepText and moves
+// the StepText past it, an empty string is returned when there is none
+func dataPlaceholder(stepText *string) string {
+	for {
+		i := strings.Index(*stepText, "{{")
+		if i < 0 {
+			return ""
+		}
+		placeholder := (*stepText)[i:]
+		*stepText = (*stepText)[i+2:]
+		if strings.HasPrefix(placeholder, "{{d}}") || strings.HasPrefix(placeholder, "{{s}}") {
+			*stepText = (*stepText)[3:]
+			return placeholder[:5]
+		}
+	}
+}
+
+// placeholders adds the text with the <placeholders> of the outlines
+// highlighted and the rest of the text in given colour
+func (p *prettyPrinter) placeholders(s *styled, color, text string) {
+	for {
+		start := strings.Index(text, "<")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexAny(text[start+1:], "<>")
+		if end <= 0 || text[start+1+end] != '>' {
+			s.add(color, text[:start+1])
+			text = text[start+1:]
+			continue
+		}
+		end += start + 2
+		s.add(color, text[:start])
+		s.add(colorPlaceholder, text[start:end])
+		text = text[end:]
+	}
+	s.add(color, text)
+}
+
+// table adds the rows of the table with the columns padded to the widest
+// cell, the cells with a number are highlighted
+func (p *prettyPrinter) table(level int, table object.Table) {
+	var widths []int
+	for _, row := range table {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := formatter.DisplayWidth(cell.Literal); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	for _, row := range table {
+		if len(row) == 0 {
+			continue
+		}
+		s := p.start(level).add(colorComment, "|")
+		for i, cell := range row {
+			s.add("", " ")
+			if _, err := strconv.ParseFloat(cell.Literal, 64); err == nil {
+				s.add(colorNumber, cell.Literal)
+			} else {
+				p.placeholders(s, "", cell.Literal)
+			}
+			s.add("", strings.Repeat(" ", widths[i]-formatter.DisplayWidth(cell.Literal)+1))
+			s.add(colorComment, "|")
+		}
+		p.end(s, 0)
+	}
+}
+
+func (p *prettyPrinter) docString(level int, docString *object.DocString) {
+	fence := docString.Delimiter
+	if fence == "" {
+		fence = `"""`
+	}
+	p.end(p.start(level).add(colorComment, fence+docString.MediaType), 0)
+	for _, line := range formatter.DocStringLines(docString) {
+		s := p.start(level)
+		if line == "" {
+			s = &styled{p: p}
+		}
+		p.placeholders(s, colorString, line)
+		p.end(s, 0)
+	}
+	p.end(p.start(level).add(colorComment, fence), 0)
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func isWordByte(ch byte) bool {
+	return isDigit(ch) || ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch >= 0x80
+}
@@ -0,0 +1,142 @@
+package reporter
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const prettyInput = `@shop
+Feature: Cart
+	Adding things to the cart
+
+	Scenario: add one
+		When I add 12 "apple pie" to item2 for 3.5
+		Then the cart has:
+			| item | price |
+			| 日本 | 2     |
+		And the receipt is:
+			"""
+			total <count>
+			"""
+
+	Rule: discounts
+		Scenario Outline: add <count>
+			When I add <count> "<item>"
+
+			@fruit
+			Examples:
+				| count | item   |
+				| 2     | banana |
+`
+
+func TestPrettyReporter(t *testing.T) {
+	featureSet := parseFeatureSet(t, prettyInput)
+	var out bytes.Buffer
+	if err := Report([]Reporter{NewPrettyReporter(&out)}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `@shop
+Feature: Cart                      # report.feature:2
+  Adding things to the cart
+
+  Scenario: add one                # report.feature:5
+    When I add 12 "apple pie" to item2 for 3.5
+    Then the cart has:
+      | item | price |
+      | 日本 | 2     |
+    And the receipt is:
+      """
+      total <count>
+      """
+
+  Rule: discounts                  # report.feature:15
+
+    Scenario Outline: add <count>  # report.feature:16
+      When I add <count> "<item>"
+
+      @fruit
+      Examples:
+        | count | item   |
+        | 2     | banana |
+`
+	if out.String() != expected {
+		t.Fatalf("Output mismatch, expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestPrettyReporterColor(t *testing.T) {
+	featureSet := parseFeatureSet(t, prettyInput)
+	var out bytes.Buffer
+	r := &prettyReporter{out: &out, color: true}
+	if err := Report([]Reporter{r}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	expected := []string{
+		colorTag + "@shop" + colorReset + "\n",
+		colorKeyword + "Feature:" + colorReset + " Cart ",
+		colorComment + "# report.feature:5" + colorReset + "\n",
+		colorKeyword + "When" + colorReset + " I add " + colorNumber + "12" + colorReset + " " +
+			colorString + `"apple pie"` + colorReset + " to item2 for " + colorNumber + "3.5" + colorReset + "\n",
+		colorComment + "|" + colorReset + " 日本 " + colorComment + "|" + colorReset + " " + colorNumber + "2" + colorReset + "     ",
+		colorString + "total " + colorReset + colorPlaceholder + "<count>" + colorReset + "\n",
+		" add " + colorPlaceholder + "<count>" + colorReset + "  ",
+		colorString + `"` + colorReset + colorPlaceholder + "<item>" + colorReset + colorString + `"` + colorReset + "\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(got, s) {
+			t.Fatalf("Expected the output to contain %q but got %q", s, got)
+		}
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	file, err := ioutil.TempFile("", "gorkin-pretty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	info, err := devNull.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		t.Skipf("%v is not a character device", os.DevNull)
+	}
+
+	noColor, set := os.LookupEnv("NO_COLOR")
+	defer func() {
+		if set {
+			os.Setenv("NO_COLOR", noColor)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	tests := []struct {
+		noColor  string
+		out      io.Writer
+		expected bool
+	}{
+		{"", &bytes.Buffer{}, false},
+		{"", file, false},
+		{"", devNull, true},
+		{"1", devNull, false},
+	}
+	for _, tt := range tests {
+		os.Setenv("NO_COLOR", tt.noColor)
+		if got := useColor(tt.out); got != tt.expected {
+			t.Fatalf("Color mismatch for %T with NO_COLOR=%q, expected %v, got %v", tt.out, tt.noColor, tt.expected, got)
+		}
+	}
+}
@@ -1,13 +1,63 @@
 package reporter
 
 import (
-	"fmt"
 	"github.com/dpakach/gorkin/object"
 	"github.com/dpakach/gorkin/parser"
 	"io"
 	"strconv"
+	"strings"
 )
 
+func init() {
+	Register("text", NewTextReporter)
+}
+
+// textReporter writes the features as the indented text dump of PrintResult
+type textReporter struct {
+	out io.Writer
+	err error
+}
+
+// NewTextReporter creates the Reporter of the text format, the errors are not
+// written as they are reported to the user apart from the output
+func NewTextReporter(out io.Writer) Reporter {
+	r := &textReporter{}
+	r.out = &errWriter{w: out, err: &r.err}
+	return r
+}
+
+func (r *textReporter) Start() error {
+	return nil
+}
+
+func (r *textReporter) Feature(feature *object.Feature) error {
+	PrintFeature(r.out, feature)
+	return r.err
+}
+
+func (r *textReporter) Error(err *parser.FileError) error {
+	return nil
+}
+
+func (r *textReporter) Finish() error {
+	return r.err
+}
+
+// errWriter keeps the first error of the writer and skips the writes after it
+type errWriter struct {
+	w   io.Writer
+	err *error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if *ew.err != nil {
+		return 0, *ew.err
+	}
+	n, err := ew.w.Write(p)
+	*ew.err = err
+	return n, err
+}
+
 // ParseAndReport Parses the input Parser and writes the output in given writer
 func ParseAndReport(p *parser.Parser, out io.Writer) {
 	res := p.Parse()
@@ -23,91 +73,141 @@ func ParseAndReport(p *parser.Parser, out io.Writer) {
 
 // PrintResult Parses the input FeatureSet and writes the output in given writer
 func PrintResult(out io.Writer, featureSet *object.FeatureSet) {
-	for _, feature := range featureSet.Features {
-		io.WriteString(out, "\n")
-		io.WriteString(out, "Feature:\n")
-		io.WriteString(out, "\tTitle: ")
-		io.WriteString(out, feature.Title)
-		io.WriteString(out, "\n\t")
+	for i := range featureSet.Features {
+		PrintFeature(out, &featureSet.Features[i])
+	}
+}
+
+// PrintFeature writes the text dump of the feature in given writer
+func PrintFeature(out io.Writer, feature *object.Feature) {
+	io.WriteString(out, "\n")
+	io.WriteString(out, "Feature:\n")
+	io.WriteString(out, "\tTitle: ")
+	io.WriteString(out, feature.Title)
+	io.WriteString(out, "\n\t")
+	io.WriteString(out, "Tags: ")
+	io.WriteString(out, "[")
+	for _, tag := range feature.Tags {
+		io.WriteString(out, " "+tag+" ")
+	}
+	io.WriteString(out, "]")
+	PrintDescription(out, feature.Description, 1)
+	io.WriteString(out, "\n\n\t")
+	io.WriteString(out, "Background:\n\t")
+	if feature.Background != nil {
+		io.WriteString(out, "\t")
+		PrintSteps(out, feature.Background.Steps, 2)
+	}
+	io.WriteString(out, "\n\t")
+	PrintScenarios(out, feature.Scenarios)
+	for _, rule := range feature.Rules {
+		io.WriteString(out, "\n\tRule:\n\t\t")
+		io.WriteString(out, "Title: ")
+		io.WriteString(out, rule.Title)
+		io.WriteString(out, "\n\t\t")
 		io.WriteString(out, "Tags: ")
 		io.WriteString(out, "[")
-		for _, tag := range feature.Tags {
+		for _, tag := range rule.Tags {
 			io.WriteString(out, " "+tag+" ")
 		}
 		io.WriteString(out, "]")
-		io.WriteString(out, "\n\n\t")
-		io.WriteString(out, "Background:\n\t")
-		if feature.Background != nil {
+		PrintDescription(out, rule.Description, 2)
+		io.WriteString(out, "\n\n\t\t")
+		io.WriteString(out, "Background:\n\t\t")
+		if rule.Background != nil {
 			io.WriteString(out, "\t")
-			PrintSteps(out, feature.Background.Steps, 2)
+			PrintSteps(out, rule.Background.Steps, 3)
 		}
 		io.WriteString(out, "\n\t")
-		var titleString string
-		var steps []object.Step
-		var table object.Table
-		var tags []string
-		var lineNumber int
-		for _, scenario := range feature.Scenarios {
-			io.WriteString(out, "\n\tScenario")
-			outlineObj, ok := scenario.(*object.ScenarioOutline)
-			isOutline := ok
-			if isOutline {
-				io.WriteString(out, " Outline:\n\t\t")
-				tags = outlineObj.Tags
-				titleString = outlineObj.ScenarioText
-				io.WriteString(out, "Title: ")
-				io.WriteString(out, titleString)
-				io.WriteString(out, "\n")
-				for _, s := range outlineObj.GetScenarios() {
-					steps = outlineObj.Steps
-					tables := outlineObj.Tables
-					lineNumber = s.LineNumber
-					io.WriteString(out, "\t\tTitle: ")
-					io.WriteString(out, titleString)
-					io.WriteString(out, ":")
-					io.WriteString(out, strconv.Itoa(lineNumber))
-					io.WriteString(out, "\n\t\t\t\t")
-					io.WriteString(out, "Tags: ")
-					io.WriteString(out, "[")
-					for _, tag := range tags {
-						io.WriteString(out, " "+tag+" ")
-					}
-					io.WriteString(out, "]")
-					io.WriteString(out, "\n\t\t\t\t")
-					PrintSteps(out, steps, 4)
-					for _, table := range tables {
-						PrintTable(out, table)
-					}
-					io.WriteString(out, "\n\t")
-				}
-			} else {
-				scenarioObj := scenario.(*object.Scenario)
-				io.WriteString(out, ":\n\t\t")
-				tags = scenarioObj.Tags
-				titleString = scenarioObj.ScenarioText
-				steps = scenarioObj.Steps
-				lineNumber = scenarioObj.LineNumber
-
-				io.WriteString(out, "Title: ")
+		PrintScenarios(out, rule.Scenarios)
+	}
+}
+
+// PrintScenarios Parses the collection of ScenarioType and writes the output in given writer
+func PrintScenarios(out io.Writer, scenarios []object.ScenarioType) {
+	var titleString string
+	var steps []object.Step
+	var table object.Table
+	var tags []string
+	var lineNumber int
+	for _, scenario := range scenarios {
+		io.WriteString(out, "\n\tScenario")
+		outlineObj, ok := scenario.(*object.ScenarioOutline)
+		isOutline := ok
+		if isOutline {
+			io.WriteString(out, " Outline:\n\t\t")
+			tags = outlineObj.Tags
+			titleString = outlineObj.ScenarioText
+			io.WriteString(out, "Title: ")
+			io.WriteString(out, titleString)
+			PrintDescription(out, outlineObj.Description, 2)
+			io.WriteString(out, "\n")
+			for _, s := range outlineObj.GetScenarios() {
+				steps = outlineObj.Steps
+				tables := outlineObj.Tables
+				lineNumber = s.LineNumber
+				io.WriteString(out, "\t\tTitle: ")
 				io.WriteString(out, titleString)
 				io.WriteString(out, ":")
 				io.WriteString(out, strconv.Itoa(lineNumber))
-				io.WriteString(out, "\n\t\t")
+				io.WriteString(out, "\n\t\t\t\t")
 				io.WriteString(out, "Tags: ")
 				io.WriteString(out, "[")
 				for _, tag := range tags {
 					io.WriteString(out, " "+tag+" ")
 				}
 				io.WriteString(out, "]")
-				io.WriteString(out, "\n\t\t")
-				PrintSteps(out, steps, 2)
-				PrintTable(out, table)
+				io.WriteString(out, "\n\t\t\t\t")
+				PrintSteps(out, steps, 4)
+				for i, table := range tables {
+					if i < len(outlineObj.TableDescriptions) {
+						PrintDescription(out, outlineObj.TableDescriptions[i], 2)
+					}
+					PrintTable(out, table)
+				}
 				io.WriteString(out, "\n\t")
 			}
+		} else {
+			scenarioObj := scenario.(*object.Scenario)
+			io.WriteString(out, ":\n\t\t")
+			tags = scenarioObj.Tags
+			titleString = scenarioObj.ScenarioText
+			steps = scenarioObj.Steps
+			lineNumber = scenarioObj.LineNumber
+
+			io.WriteString(out, "Title: ")
+			io.WriteString(out, titleString)
+			io.WriteString(out, ":")
+			io.WriteString(out, strconv.Itoa(lineNumber))
+			io.WriteString(out, "\n\t\t")
+			io.WriteString(out, "Tags: ")
+			io.WriteString(out, "[")
+			for _, tag := range tags {
+				io.WriteString(out, " "+tag+" ")
+			}
+			io.WriteString(out, "]")
+			PrintDescription(out, scenarioObj.Description, 2)
+			io.WriteString(out, "\n\t\t")
+			PrintSteps(out, steps, 2)
+			PrintTable(out, table)
+			io.WriteString(out, "\n\t")
 		}
 	}
 }
 
+// PrintDescription writes the description lines with their original
+// indentation in given writer
+func PrintDescription(out io.Writer, description string, tab int) {
+	if description == "" {
+		return
+	}
+	indent := strings.Repeat("\t", tab)
+	io.WriteString(out, "\n"+indent+"Description:")
+	for _, line := range strings.Split(description, "\n") {
+		io.WriteString(out, "\n"+indent+line)
+	}
+}
+
 // PrintSteps Parses the collection of Steps and writes the output in given writer
 func PrintSteps(out io.Writer, steps []object.Step, tab int) {
 	for _, step := range steps {
@@ -131,7 +231,6 @@ func PrintSteps(out io.Writer, steps []object.Step, tab int) {
 
 // PrintTable Parses the Table and writes the output in given writer
 func PrintTable(out io.Writer, table object.Table) {
-	fmt.Println(table)
 	if len(table) > 0 {
 		io.WriteString(out, "\n\t\tTable:")
 		for _, row := range table {
@@ -0,0 +1,130 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+// Reporter writes the features and the errors of a run in an output format
+//
+// Start is called once before any feature or error, then Feature is called
+// for every parsed feature and Error for every file that failed to parse.
+// Finish is called last and writes what is left of the output.
+type Reporter interface {
+	Start() error
+	Feature(feature *object.Feature) error
+	Error(err *parser.FileError) error
+	Finish() error
+}
+
+// Format creates a Reporter writing to given writer
+type Format func(out io.Writer) Reporter
+
+// DirFormat creates a Reporter writing its output as files into given
+// directory
+type DirFormat func(dir string) Reporter
+
+var (
+	formatsMu  sync.RWMutex
+	formats    = map[string]Format{}
+	dirFormats = map[string]DirFormat{}
+)
+
+// Register makes the format available by given name, registering the same
+// name twice panics
+func Register(name string, format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if format == nil {
+		panic("reporter: Register format is nil")
+	}
+	if _, ok := formats[name]; ok {
+		panic("reporter: Register called twice for format " + name)
+	}
+	formats[name] = format
+}
+
+// RegisterDir makes the format available by given name for writing into a
+// directory, registering the same name twice panics
+func RegisterDir(name string, format DirFormat) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if format == nil {
+		panic("reporter: RegisterDir format is nil")
+	}
+	if _, ok := dirFormats[name]; ok {
+		panic("reporter: RegisterDir called twice for format " + name)
+	}
+	dirFormats[name] = format
+}
+
+// Formats returns the sorted names of the registered formats
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a Reporter of the registered format writing to given writer
+func New(name string, out io.Writer) (Reporter, error) {
+	formatsMu.RLock()
+	format, ok := formats[name]
+	formatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+	return format(out), nil
+}
+
+// NewDir creates a Reporter of the format registered by RegisterDir writing
+// into given directory
+func NewDir(name, dir string) (Reporter, error) {
+	formatsMu.RLock()
+	format, ok := dirFormats[name]
+	formatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("format %q can not be written to a directory", name)
+	}
+	return format(dir), nil
+}
+
+// Report reports the features of the FeatureSet and the errors with every
+// reporter, the first error of writing the output is returned
+func Report(reporters []Reporter, featureSet *object.FeatureSet, errs []*parser.FileError) error {
+	var firstErr error
+	for _, r := range reporters {
+		if err := report(r, featureSet, errs); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func report(r Reporter, featureSet *object.FeatureSet, errs []*parser.FileError) error {
+	if err := r.Start(); err != nil {
+		return err
+	}
+	if featureSet != nil {
+		for i := range featureSet.Features {
+			if err := r.Feature(&featureSet.Features[i]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, fileErr := range errs {
+		if err := r.Error(fileErr); err != nil {
+			return err
+		}
+	}
+	return r.Finish()
+}
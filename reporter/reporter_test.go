@@ -0,0 +1,221 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dpakach/gorkin/object"
+	"github.com/dpakach/gorkin/parser"
+)
+
+const reportInput = `@coolTag
+Feature: report
+	Scenario: first
+		Given a table
+			| a | b |
+			| 1 | 2 |
+
+	Scenario Outline: eat <fruit>
+		When I eat <fruit>
+
+		Examples:
+			| fruit  |
+			| apple  |
+			| banana |
+`
+
+func parseFeatureSet(t testing.TB, input string) *object.FeatureSet {
+	featureSet, err := parser.ParseSource("report.feature", []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return featureSet
+}
+
+// recorder records the calls of a reporter
+type recorder struct {
+	calls []string
+	fail  string
+}
+
+func (r *recorder) record(call string) error {
+	r.calls = append(r.calls, call)
+	if call == r.fail {
+		return errors.New("failed " + call)
+	}
+	return nil
+}
+
+func (r *recorder) Start() error {
+	return r.record("start")
+}
+
+func (r *recorder) Feature(feature *object.Feature) error {
+	return r.record("feature " + feature.Title)
+}
+
+func (r *recorder) Error(err *parser.FileError) error {
+	return r.record("error " + err.Path)
+}
+
+func (r *recorder) Finish() error {
+	return r.record("finish")
+}
+
+func TestReport(t *testing.T) {
+	featureSet := &object.FeatureSet{Features: []object.Feature{{Title: "one"}, {Title: "two"}}}
+	errs := []*parser.FileError{{Path: "broken.feature", Err: errors.New("broken")}}
+
+	tests := []struct {
+		fail     string
+		expected []string
+	}{
+		{"", []string{"start", "feature one", "feature two", "error broken.feature", "finish"}},
+		{"start", []string{"start"}},
+		{"feature one", []string{"start", "feature one"}},
+		{"finish", []string{"start", "feature one", "feature two", "error broken.feature", "finish"}},
+	}
+
+	for _, tt := range tests {
+		first, second := &recorder{fail: tt.fail}, &recorder{}
+		err := Report([]Reporter{first, second}, featureSet, errs)
+		if (err != nil) != (tt.fail != "") {
+			t.Fatalf("Error mismatch for %q, got %v", tt.fail, err)
+		}
+		if strings.Join(first.calls, ", ") != strings.Join(tt.expected, ", ") {
+			t.Fatalf("Calls mismatch, expected %v, got %v", tt.expected, first.calls)
+		}
+		// A failing reporter does not stop the others
+		if len(second.calls) != 5 {
+			t.Fatalf("Calls mismatch of the second reporter, expected 5, got %v", second.calls)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	found := false
+	for _, name := range Formats() {
+		found = found || name == "text"
+	}
+	if !found {
+		t.Fatalf("Expected the text format to be registered but got %v", Formats())
+	}
+
+	if _, err := New("unknown", &bytes.Buffer{}); err == nil {
+		t.Fatalf("Expected an error for an unknown format")
+	}
+
+	Register("test", func(out io.Writer) Reporter { return &recorder{} })
+	r, err := New("test", &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.(*recorder); !ok {
+		t.Fatalf("Reporter mismatch, expected *recorder, got %T", r)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected registering a format twice to panic")
+		}
+	}()
+	Register("test", func(out io.Writer) Reporter { return &recorder{} })
+}
+
+// failingWriter fails after writing n bytes
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return 0, fmt.Errorf("write failed")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestTextReporter(t *testing.T) {
+	featureSet := parseFeatureSet(t, reportInput)
+
+	var expected, got bytes.Buffer
+	PrintResult(&expected, featureSet)
+	if err := Report([]Reporter{NewTextReporter(&got)}, featureSet, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != expected.String() {
+		t.Fatalf("Output mismatch, expected %q, got %q", expected.String(), got.String())
+	}
+
+	for _, line := range []string{"\tTitle: report", "Step: When - I eat {{<fruit>}}", "\t\t\t1\t2\t"} {
+		if !strings.Contains(got.String(), line) {
+			t.Fatalf("Expected the output to contain %q but got %q", line, got.String())
+		}
+	}
+	// The table is only written as the rows
+	if strings.Contains(got.String(), "[[") {
+		t.Fatalf("Expected no table dump in the output but got %q", got.String())
+	}
+
+	err := Report([]Reporter{NewTextReporter(&failingWriter{n: 20})}, featureSet, nil)
+	if err == nil || err.Error() != "write failed" {
+		t.Fatalf("Error mismatch, expected write failed, got %v", err)
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	featureSet := parseFeatureSet(t, reportInput)
+	_, err := parser.ParseSource("broken.feature", []byte("Scenario: no feature\n"))
+	var fileErr *parser.FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("Expected a *parser.FileError but got %v", err)
+	}
+	errs := []*parser.FileError{fileErr, {Path: "missing.feature", Err: errors.New("file not found")}}
+
+	var out bytes.Buffer
+	if err := Report([]Reporter{NewJSONReporter(&out)}, featureSet, errs); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := object.UnmarshalFeatureSet(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != 1 || result.Features[0].Title != "report" {
+		t.Fatalf("Features mismatch, expected the report feature, got %+v", result.Features)
+	}
+
+	var doc struct {
+		Errors []struct {
+			URI      string
+			Message  string
+			Location *struct{ Line, Column int }
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Errors) != 2 {
+		t.Fatalf("Errors length mismatch, expected 2, got %v", len(doc.Errors))
+	}
+	if doc.Errors[0].URI != "broken.feature" || doc.Errors[0].Location == nil || doc.Errors[0].Location.Line != 1 {
+		t.Fatalf("Error mismatch, expected broken.feature on line 1, got %+v", doc.Errors[0])
+	}
+	if doc.Errors[1].Message != "file not found" || doc.Errors[1].Location != nil {
+		t.Fatalf("Error mismatch, expected file not found without location, got %+v", doc.Errors[1])
+	}
+
+	// Without any feature the features are still an array
+	out.Reset()
+	if err := Report([]Reporter{NewJSONReporter(&out)}, &object.FeatureSet{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"features": []`) || strings.Contains(out.String(), "errors") {
+		t.Fatalf("Output mismatch, expected an empty document, got %v", out.String())
+	}
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language used when a feature has no language header
+const DefaultLanguage = "en"
+
+// Dialect holds the keywords of a Gherkin language
+//
+// The fields mirror the entries of the official gherkin-languages.json, with
+// the trailing spaces of the step keywords left out
+type Dialect struct {
+	Name            string
+	Native          string
+	Feature         []string
+	Rule            []string
+	Background      []string
+	Scenario        []string
+	ScenarioOutline []string
+	Examples        []string
+	Given           []string
+	When            []string
+	Then            []string
+	And             []string
+	But             []string
+}
+
+// Keyword is a keyword of a Dialect along with its token type
+type Keyword struct {
+	Literal string
+	Type    Type
+}
+
+// Dialects contains the available Gherkin dialects keyed by language code
+var Dialects = map[string]*Dialect{
+	"en": {
+		Name:            "English",
+		Native:          "English",
+		Feature:         []string{"Feature", "Business Need", "Ability"},
+		Rule:            []string{"Rule"},
+		Background:      []string{"Background"},
+		Scenario:        []string{"Example", "Scenario"},
+		ScenarioOutline: []string{"Scenario Outline", "Scenario Template"},
+		Examples:        []string{"Examples", "Scenarios"},
+		Given:           []string{"*", "Given"},
+		When:            []string{"*", "When"},
+		Then:            []string{"*", "Then"},
+		And:             []string{"*", "And"},
+		But:             []string{"*", "But"},
+	},
+	"de": {
+		Name:            "German",
+		Native:          "Deutsch",
+		Feature:         []string{"Funktionalität", "Funktion"},
+		Rule:            []string{"Rule", "Regel"},
+		Background:      []string{"Grundlage", "Hintergrund", "Voraussetzungen", "Vorbedingungen"},
+		Scenario:        []string{"Beispiel", "Szenario"},
+		ScenarioOutline: []string{"Szenariogrundriss", "Szenarien"},
+		Examples:        []string{"Beispiele"},
+		Given:           []string{"*", "Angenommen", "Gegeben sei", "Gegeben seien"},
+		When:            []string{"*", "Wenn"},
+		Then:            []string{"*", "Dann"},
+		And:             []string{"*", "Und"},
+		But:             []string{"*", "Aber"},
+	},
+	"es": {
+		Name:            "Spanish",
+		Native:          "español",
+		Feature:         []string{"Característica", "Necesidad del negocio", "Requisito"},
+		Rule:            []string{"Regla", "Regla de negocio"},
+		Background:      []string{"Antecedentes"},
+		Scenario:        []string{"Ejemplo", "Escenario"},
+		ScenarioOutline: []string{"Esquema del escenario"},
+		Examples:        []string{"Ejemplos"},
+		Given:           []string{"*", "Dado", "Dada", "Dados", "Dadas"},
+		When:            []string{"*", "Cuando"},
+		Then:            []string{"*", "Entonces"},
+		And:             []string{"*", "Y", "E"},
+		But:             []string{"*", "Pero"},
+	},
+	"fr": {
+		Name:            "French",
+		Native:          "français",
+		Feature:         []string{"Fonctionnalité"},
+		Rule:            []string{"Règle"},
+		Background:      []string{"Contexte"},
+		Scenario:        []string{"Exemple", "Scénario"},
+		ScenarioOutline: []string{"Plan du scénario", "Plan du Scénario"},
+		Examples:        []string{"Exemples"},
+		Given: []string{
+			"*", "Soit", "Sachant que", "Sachant qu'", "Sachant",
+			"Etant donné que", "Etant donné qu'", "Etant donné", "Etant donnée", "Etant donnés", "Etant données",
+			"Étant donné que", "Étant donné qu'", "Étant donné", "Étant donnée", "Étant donnés", "Étant données",
+		},
+		When: []string{"*", "Quand", "Lorsque", "Lorsqu'"},
+		Then: []string{"*", "Alors", "Donc"},
+		And:  []string{"*", "Et que", "Et qu'", "Et"},
+		But:  []string{"*", "Mais que", "Mais qu'", "Mais"},
+	},
+	"ne": {
+		Name:            "Nepali",
+		Native:          "नेपाली",
+		Feature:         []string{"सुविधा", "विशेषता"},
+		Rule:            []string{"नियम"},
+		Background:      []string{"पृष्ठभूमी"},
+		Scenario:        []string{"परिदृश्य"},
+		ScenarioOutline: []string{"परिदृश्य रूपरेखा"},
+		Examples:        []string{"उदाहरण", "उदाहरणहरुमा"},
+		Given:           []string{"*", "दिइएको", "दिएको", "यदि"},
+		When:            []string{"*", "जब"},
+		Then:            []string{"*", "त्यसपछि", "अनी"},
+		And:             []string{"*", "र", "अनी"},
+		But:             []string{"*", "तर"},
+	},
+}
+
+// GetDialect returns the Dialect for given language code
+func GetDialect(language string) (*Dialect, bool) {
+	dialect, ok := Dialects[language]
+	return dialect, ok
+}
+
+// Keywords returns all the keywords in the dialect, longest keyword first
+//
+// Scenario outline keywords are returned with the OUTLINE type, a keyword
+// shared between multiple step types keeps the type of its first occurrence
+func (d *Dialect) Keywords() []Keyword {
+	groups := []struct {
+		literals []string
+		t        Type
+	}{
+		{d.Feature, FEATURE},
+		{d.Rule, RULE},
+		{d.Background, BACKGROUND},
+		{d.ScenarioOutline, OUTLINE},
+		{d.Scenario, SCENARIO},
+		{d.Examples, EXAMPLES},
+		{d.And, AND},
+		{d.Given, GIVEN},
+		{d.When, WHEN},
+		{d.Then, THEN},
+		{d.But, BUT},
+	}
+
+	var keywords []Keyword
+	seen := map[string]bool{}
+	for _, group := range groups {
+		for _, literal := range group.literals {
+			if seen[literal] {
+				continue
+			}
+			seen[literal] = true
+			keywords = append(keywords, Keyword{Literal: literal, Type: group.t})
+		}
+	}
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return len(keywords[i].Literal) > len(keywords[j].Literal)
+	})
+	return keywords
+}
+
+// SplitOutline splits a scenario outline keyword into its scenario part and
+// the remaining outline part, eg. "Scenario Outline" into "Scenario" and
+// "Outline". Keywords without a scenario prefix are returned whole.
+func (d *Dialect) SplitOutline(keyword string) (string, string) {
+	for _, scenario := range d.Scenario {
+		if strings.HasPrefix(keyword, scenario+" ") {
+			return scenario, strings.TrimSpace(keyword[len(scenario):])
+		}
+	}
+	return keyword, ""
+}
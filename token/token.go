@@ -4,10 +4,15 @@ package token
 type Type int
 
 // Token represents each token in parsing through Gherkin
+//
+// Column is the 1 based position of the token in its line counted in runes,
+// Offset is the byte offset of the token from the start of the input
 type Token struct {
 	Type       Type
 	Literal    string
 	LineNumber int
+	Column     int
+	Offset     int
 }
 
 // TokenTypes used in Gherkin
@@ -31,6 +36,7 @@ const (
 
 	// Data Structures types in gherkin
 	FEATURE
+	RULE
 	SCENARIO
 	OUTLINE
 	EXAMPLES
@@ -48,6 +54,8 @@ func (token Type) String() string {
 	switch token {
 	case FEATURE:
 		return "Feature"
+	case RULE:
+		return "Rule"
 	case SCENARIO:
 		return "Scenario"
 	case BACKGROUND:
@@ -95,20 +103,17 @@ func (token Type) String() string {
 	return "Illegal"
 }
 
-var keywords = map[string]Type{
-	"Feature":    FEATURE,
-	"Scenario":   SCENARIO,
-	"When":       WHEN,
-	"Given":      GIVEN,
-	"Then":       THEN,
-	"But":        BUT,
-	"And":        AND,
-	"Outline":    OUTLINE,
-	"Examples":   EXAMPLES,
-	"Background": BACKGROUND,
+var keywords = keywordTypes(Dialects[DefaultLanguage])
+
+func keywordTypes(d *Dialect) map[string]Type {
+	res := map[string]Type{}
+	for _, keyword := range d.Keywords() {
+		res[keyword.Literal] = keyword.Type
+	}
+	return res
 }
 
-// GherkinKeyword represents available keywords in Gherkin and their token id
+// GherkinKeyword represents available keywords in the default Gherkin dialect and their token id
 var GherkinKeyword = keywords
 
 // LookupIdent returns Type for given Identifier